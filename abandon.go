@@ -0,0 +1,22 @@
+package conc
+
+import (
+	"slices"
+)
+
+// abandon detaches running goroutines from nursery and reports their indices.
+func (n *nursery) abandon() {
+	close(n.abandoned)
+
+	n.runningMu.Lock()
+	indices := make([]int, 0, len(n.running))
+	for index := range n.running {
+		indices = append(indices, index)
+	}
+	n.runningMu.Unlock()
+
+	slices.Sort(indices)
+	if n.onAbandon != nil {
+		n.onAbandon(indices)
+	}
+}
@@ -35,6 +35,19 @@ func BenchmarkNursery(b *testing.B) {
 		}
 	})
 
+	b.Run("WithRoutines/NoWork/WithoutPanicRecovery", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			conc.Block(func(n conc.Nursery) error {
+				for j := 0; j < benchRoutineCount; j++ {
+					n.Go(func() error {
+						return nil
+					})
+				}
+				return nil
+			}, conc.WithoutPanicRecovery())
+		}
+	})
+
 	b.Run("WithRoutines/Nested/NoWork", func(b *testing.B) {
 		for i := 0; i < b.N; i++ {
 			conc.Block(func(n conc.Nursery) error {
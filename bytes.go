@@ -0,0 +1,27 @@
+package conc
+
+// GoBytes is the same as n.Go but routine acquires estimated bytes from
+// in-flight byte budget (see WithInFlightByteLimit) before starting. It blocks
+// until budget is available or nursery is canceled, routine isn't executed in
+// the latter case. Estimates above budget are clamped so routine runs alone.
+func GoBytes(n Nursery, estimated int64, routine Routine) {
+	impl, ok := n.(*nursery)
+	if !ok || impl.bytes == nil {
+		n.Go(routine)
+		return
+	}
+	impl.goBytes(estimated, routine)
+}
+
+func (n *nursery) goBytes(estimated int64, routine Routine) {
+	estimated = min(max(estimated, 0), n.bytes.limit())
+	if !n.bytes.acquire(n, estimated) {
+		return
+	}
+
+	release := func() { n.bytes.release(estimated) }
+	n.goReleasing(func() error {
+		defer release()
+		return routine()
+	}, release)
+}
@@ -0,0 +1,89 @@
+package conc
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGoBytes(t *testing.T) {
+	var mu sync.Mutex
+	var inFlight, peak int64
+	active, oversizedPeers := 0, 0
+
+	task := func(size int64, oversized bool) Routine {
+		return func() error {
+			mu.Lock()
+			inFlight += size
+			peak = max(peak, inFlight)
+			active++
+			if oversized && active > 1 {
+				oversizedPeers++
+			}
+			mu.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			mu.Lock()
+			inFlight -= size
+			active--
+			mu.Unlock()
+			return nil
+		}
+	}
+
+	Block(func(n Nursery) error {
+		for i := 0; i < 20; i++ {
+			GoBytes(n, 40, task(40, false))
+		}
+		// Clamped to budget.
+		GoBytes(n, 1000, task(100, true))
+		for i := 0; i < 5; i++ {
+			GoBytes(n, 30, task(30, false))
+		}
+		return nil
+	}, WithInFlightByteLimit(100))
+
+	if peak > 100 {
+		t.Fatalf("%v bytes in flight, budget is 100", peak)
+	}
+	if oversizedPeers != 0 {
+		t.Fatal("oversized task didn't run alone")
+	}
+}
+
+func TestGoBytesDropped(t *testing.T) {
+	var impl *nursery
+	Block(func(n Nursery) error {
+		impl = n.(*nursery)
+		n.Go(func() error {
+			// Hold the only goroutine slot until canceled.
+			<-n.Done()
+			return nil
+		})
+
+		spawned := make(chan struct{})
+		go func() {
+			defer close(spawned)
+			// Dropped once nursery is canceled.
+			GoBytes(n, 10, func() error {
+				t.Error("dropped routine executed")
+				return nil
+			})
+		}()
+
+		// Wait for whole budget to be acquired.
+		for impl.bytes.tryAcquire(1) {
+			impl.bytes.release(1)
+			time.Sleep(time.Millisecond)
+		}
+
+		impl.cancel()
+		<-spawned
+		return nil
+	}, WithMaxGoroutines(1), WithInFlightByteLimit(10))
+
+	if !impl.bytes.tryAcquire(10) {
+		t.Fatal("byte budget of dropped routine not released")
+	}
+}
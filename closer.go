@@ -0,0 +1,46 @@
+package conc
+
+import (
+	"cmp"
+	"errors"
+	"io"
+	"slices"
+)
+
+type closer struct {
+	priority int
+	io.Closer
+}
+
+// RegisterCloser registers c to be closed once block of nursery n has ended
+// and all goroutines have returned. Closers are closed in ascending priority
+// order, closers with equal priority are closed in registration order. If
+// block panics, closers are closed before panic is forwarded but goroutines
+// may still be running and close errors are ignored.
+func RegisterCloser(n Nursery, priority int, c io.Closer) {
+	impl := mustNursery(n, "RegisterCloser")
+	impl.closersMu.Lock()
+	defer impl.closersMu.Unlock()
+	impl.closers = append(impl.closers, closer{priority, c})
+}
+
+// closeResources closes registered closers in ascending priority order and
+// returns joined close errors.
+func (n *nursery) closeResources() error {
+	n.closersMu.Lock()
+	closers := n.closers
+	n.closers = nil
+	n.closersMu.Unlock()
+
+	slices.SortStableFunc(closers, func(a, b closer) int {
+		return cmp.Compare(a.priority, b.priority)
+	})
+
+	var errs []error
+	for _, c := range closers {
+		if err := c.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,80 @@
+package conc
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"testing"
+)
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
+
+func TestRegisterCloser(t *testing.T) {
+	t.Run("PriorityOrder", func(t *testing.T) {
+		var closed []int
+		closeFn := func(i int) io.Closer {
+			return closerFunc(func() error {
+				closed = append(closed, i)
+				return nil
+			})
+		}
+
+		Block(func(n Nursery) error {
+			for _, p := range []int{2, 1, 3} {
+				priority := p
+				n.Go(func() error {
+					RegisterCloser(n, priority, closeFn(priority))
+					return nil
+				})
+			}
+			return nil
+		})
+
+		if !slices.Equal(closed, []int{1, 2, 3}) {
+			t.Fatalf("closers closed in order %v instead of [1 2 3]", closed)
+		}
+	})
+
+	t.Run("CloseError", func(t *testing.T) {
+		err := Block(func(n Nursery) error {
+			RegisterCloser(n, 0, closerFunc(func() error {
+				return io.ErrClosedPipe
+			}))
+			return nil
+		})
+
+		if !errors.Is(err, io.ErrClosedPipe) {
+			t.Fatalf("block returned %v instead of close error", err)
+		}
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		closed := false
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("block didn't forward goroutine panic")
+				}
+			}()
+
+			Block(func(n Nursery) error {
+				RegisterCloser(n, 0, closerFunc(func() error {
+					closed = true
+					return nil
+				}))
+				n.Go(func() error {
+					panic("foo")
+				})
+				return nil
+			})
+		}()
+
+		if !closed {
+			t.Fatal("registered closer not closed on panic")
+		}
+	})
+}
@@ -0,0 +1,162 @@
+package conc
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrNurseryDone is the panic value of goroutines spawned in a closed nursery.
+var ErrNurseryDone = errors.New("nursery is done")
+
+type collectorKey[T any] struct{}
+
+type collector[T any] struct {
+	mu      sync.Mutex
+	results []T
+}
+
+func getCollector[T any](n *nursery) *collector[T] {
+	c, _ := n.collectors.LoadOrStore(collectorKey[T]{}, &collector[T]{})
+	return c.(*collector[T])
+}
+
+// Collect executes job in a separate goroutine of nursery n and collects its
+// result if it succeeds. Collected results are retrieved using
+// CloseAndCollect. Error returned by job is forwarded to the nursery. If n
+// wasn't created by Block, job is executed using n.Go and its result is
+// discarded.
+func Collect[T any](n Nursery, job Job[T]) {
+	impl, ok := n.(*nursery)
+	if !ok {
+		n.Go(func() error {
+			_, err := job(n)
+			return err
+		})
+		return
+	}
+
+	c := getCollector[T](impl)
+	n.Go(func() error {
+		v, err := job(n)
+		if err != nil {
+			return err
+		}
+
+		c.mu.Lock()
+		c.results = append(c.results, v)
+		c.mu.Unlock()
+		return nil
+	})
+}
+
+// CloseAndCollect stops nursery n from accepting new goroutines, waits for
+// running ones to return and returns results collected with Collect in
+// completion order. It must be called from block function. Spawning a
+// goroutine in a closed nursery panics with ErrNurseryDone.
+func CloseAndCollect[T any](n Nursery) []T {
+	impl := mustNursery(n, "CloseAndCollect")
+	impl.closeAndWait()
+
+	c := getCollector[T](impl)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.results
+}
+
+// closeAndWait closes nursery and waits until block goroutine is the only one
+// remaining.
+func (n *nursery) closeAndWait() {
+	n.closed.Store(true)
+
+	ch := make(chan struct{})
+	n.drainMu.Lock()
+	n.drained = ch
+	n.drainMu.Unlock()
+
+	if n.routinesCount.Load() == 1 {
+		n.drainMu.Lock()
+		if n.drained == ch {
+			// Nothing to wait for.
+			n.drained = nil
+			n.drainMu.Unlock()
+			return
+		}
+		n.drainMu.Unlock()
+	}
+
+	<-ch
+}
+
+// notifyDrained wakes up goroutine waiting in closeAndWait, if any.
+func (n *nursery) notifyDrained() {
+	n.drainMu.Lock()
+	defer n.drainMu.Unlock()
+
+	if n.drained != nil {
+		close(n.drained)
+		n.drained = nil
+	}
+}
+
+// CollectFor returns an emit function collecting values emitted during window
+// following the first emit. Values emitted after window are dropped. Results
+// function blocks until window has elapsed or nursery n is done and returns
+// collected values in emit order. If nothing is emitted during window
+// following call to CollectFor, collection ends and results returns an empty
+// slice.
+func CollectFor[T any](n Nursery, window time.Duration) (emit func(T), results func() []T) {
+	var mu sync.Mutex
+	var values []T
+	started := false
+	closed := false
+	done := make(chan struct{})
+
+	end := func() {
+		mu.Lock()
+		closed = true
+		mu.Unlock()
+		close(done)
+	}
+
+	// Ends collection if nothing is emitted.
+	idle := time.AfterFunc(window, func() {
+		mu.Lock()
+		if started {
+			mu.Unlock()
+			return
+		}
+		started = true
+		mu.Unlock()
+		end()
+	})
+
+	emit = func(v T) {
+		mu.Lock()
+		defer mu.Unlock()
+		if closed {
+			return
+		}
+		if !started {
+			started = true
+			idle.Stop()
+			time.AfterFunc(window, end)
+		}
+		values = append(values, v)
+	}
+
+	results = func() []T {
+		select {
+		case <-done:
+		case <-n.Done():
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		closed = true
+		return slices.Clone(values)
+	}
+
+	return emit, results
+}
@@ -0,0 +1,136 @@
+package conc
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCloseAndCollect(t *testing.T) {
+	t.Run("Results", func(t *testing.T) {
+		var results []int
+		Block(func(n Nursery) error {
+			for i := 0; i < 5; i++ {
+				value := i
+				Collect(n, func(ctx context.Context) (int, error) {
+					time.Sleep(time.Duration(5-value) * time.Millisecond)
+					return value, nil
+				})
+			}
+
+			results = CloseAndCollect[int](n)
+			return nil
+		})
+
+		slices.Sort(results)
+		if !slices.Equal(results, []int{0, 1, 2, 3, 4}) {
+			t.Fatalf("collected %v instead of [0 1 2 3 4]", results)
+		}
+	})
+
+	t.Run("SubmitAfterClose", func(t *testing.T) {
+		var panicValue any
+		Block(func(n Nursery) error {
+			CloseAndCollect[int](n)
+
+			func() {
+				defer func() {
+					panicValue = recover()
+				}()
+				n.Go(func() error {
+					return nil
+				})
+			}()
+			return nil
+		})
+
+		if panicValue != ErrNurseryDone {
+			t.Fatalf("submission after close panicked with %v instead of ErrNurseryDone", panicValue)
+		}
+	})
+
+	t.Run("ConcurrentSubmit", func(t *testing.T) {
+		var results []int
+		var accepted atomic.Int32
+		var wg sync.WaitGroup
+
+		Block(func(n Nursery) error {
+			// Submit from goroutines outside nursery racing with close.
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer func() {
+						if p := recover(); p != ErrNurseryDone {
+							t.Errorf("submission panicked with %v instead of ErrNurseryDone", p)
+						}
+					}()
+					for {
+						Collect(n, func(ctx context.Context) (int, error) {
+							return 0, nil
+						})
+						accepted.Add(1)
+					}
+				}()
+			}
+
+			time.Sleep(time.Millisecond)
+			results = CloseAndCollect[int](n)
+			wg.Wait()
+			return nil
+		})
+
+		if len(results) != int(accepted.Load()) {
+			t.Fatalf("collected %v results out of %v accepted submissions", len(results), accepted.Load())
+		}
+	})
+}
+
+func TestCollectFor(t *testing.T) {
+	t.Run("Window", func(t *testing.T) {
+		var results []int
+		Block(func(n Nursery) error {
+			emit, collected := CollectFor[int](n, 20*time.Millisecond)
+
+			n.Go(func() error {
+				emit(1)
+				emit(2)
+				time.Sleep(5 * time.Millisecond)
+				emit(3)
+				return nil
+			})
+			n.Go(func() error {
+				time.Sleep(50 * time.Millisecond)
+				emit(4)
+				return nil
+			})
+
+			results = collected()
+			return nil
+		})
+
+		if !slices.Equal(results, []int{1, 2, 3}) {
+			t.Fatalf("collected %v instead of [1 2 3]", results)
+		}
+	})
+
+	t.Run("NoEmit", func(t *testing.T) {
+		var results []int
+		start := time.Now()
+		Block(func(n Nursery) error {
+			_, collected := CollectFor[int](n, 10*time.Millisecond)
+			results = collected()
+			return nil
+		})
+
+		if len(results) != 0 {
+			t.Fatalf("collected %v instead of nothing", results)
+		}
+		if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+			t.Fatalf("results returned after %v, before window elapsed", elapsed)
+		}
+	})
+}
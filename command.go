@@ -0,0 +1,57 @@
+package conc
+
+import (
+	"bytes"
+	"os/exec"
+	"sync"
+)
+
+// GoCommandOutput runs the named program with the given arguments in a
+// separate goroutine of nursery n. Command is killed if nursery is canceled.
+// Standard output and error are captured in returned buffers, they must not
+// be read before done returns. done blocks until command exits and returns
+// its error.
+func GoCommandOutput(n Nursery, name string, args ...string) (stdout, stderr *bytes.Buffer, done func() error) {
+	stdout, stderr = &bytes.Buffer{}, &bytes.Buffer{}
+
+	cmd := exec.CommandContext(n, name, args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	var mu sync.Mutex
+	var err error
+	started := false
+	finished := make(chan struct{})
+
+	// Command may never start if nursery is canceled.
+	stop := AfterFunc(n, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if !started {
+			started = true
+			err = n.Err()
+			close(finished)
+		}
+	})
+
+	n.Go(func() error {
+		stop()
+
+		mu.Lock()
+		if started {
+			mu.Unlock()
+			return nil
+		}
+		started = true
+		mu.Unlock()
+
+		defer close(finished)
+		err = cmd.Run()
+		return err
+	})
+
+	return stdout, stderr, func() error {
+		<-finished
+		return err
+	}
+}
@@ -0,0 +1,38 @@
+package conc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGoCommandOutput(t *testing.T) {
+	t.Run("Echo", func(t *testing.T) {
+		Block(func(n Nursery) error {
+			stdout, _, done := GoCommandOutput(n, "echo", "hello")
+			if err := done(); err != nil {
+				t.Fatal(err)
+			}
+			if stdout.String() != "hello\n" {
+				t.Fatalf("captured stdout is %q instead of %q", stdout.String(), "hello\n")
+			}
+			return nil
+		})
+	})
+
+	t.Run("CancelKillsCommand", func(t *testing.T) {
+		var err error
+		start := time.Now()
+		Block(func(n Nursery) error {
+			_, _, done := GoCommandOutput(n, "sleep", "10")
+			err = done()
+			return nil
+		}, WithTimeout(10*time.Millisecond), WithIgnoreErrors())
+
+		if err == nil {
+			t.Fatal("killed command returned a nil error")
+		}
+		if time.Since(start) > time.Second {
+			t.Fatal("canceling block didn't kill command")
+		}
+	})
+}
@@ -0,0 +1,28 @@
+// Package conctest provides utilities for testing code built on top of conc.
+package conctest
+
+import (
+	"github.com/negrel/conc"
+)
+
+// CapturePanic executes fn and recovers a [conc.GoroutinePanic] forwarded by a
+// nursery block. It returns recovered panic and true if fn panicked. Panics
+// with a value that isn't a [conc.GoroutinePanic] are re-raised.
+func CapturePanic(fn func()) (gp conc.GoroutinePanic, panicked bool) {
+	defer func() {
+		v := recover()
+		if v == nil {
+			return
+		}
+
+		var isGoroutinePanic bool
+		gp, isGoroutinePanic = v.(conc.GoroutinePanic)
+		if !isGoroutinePanic {
+			panic(v)
+		}
+		panicked = true
+	}()
+
+	fn()
+	return
+}
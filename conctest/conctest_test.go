@@ -0,0 +1,42 @@
+package conctest
+
+import (
+	"testing"
+
+	"github.com/negrel/conc"
+)
+
+func TestCapturePanic(t *testing.T) {
+	t.Run("PanicInGoroutine", func(t *testing.T) {
+		gp, panicked := CapturePanic(func() {
+			conc.Block(func(n conc.Nursery) error {
+				n.Go(func() error {
+					panic("foo")
+				})
+				return nil
+			})
+		})
+
+		if !panicked {
+			t.Fatal("panic not captured")
+		}
+		if gp.Value != "foo" {
+			t.Fatalf("captured panic value is %v instead of foo", gp.Value)
+		}
+	})
+
+	t.Run("NoPanic", func(t *testing.T) {
+		_, panicked := CapturePanic(func() {
+			conc.Block(func(n conc.Nursery) error {
+				n.Go(func() error {
+					return nil
+				})
+				return nil
+			})
+		})
+
+		if panicked {
+			t.Fatal("panic captured while block didn't panic")
+		}
+	})
+}
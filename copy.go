@@ -0,0 +1,50 @@
+package conc
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+)
+
+// CopyPair is a copy from Src to Dst.
+type CopyPair struct {
+	Dst io.Writer
+	Src io.Reader
+}
+
+// CopyAll copies each pair in a separate goroutine (see io.Copy), use
+// WithMaxGoroutines option to bound number of concurrent copies. Copies are
+// aborted once context is canceled. It returns total number of bytes copied
+// and block error.
+func CopyAll(ctx context.Context, pairs []CopyPair, opts ...BlockOption) (int64, error) {
+	var total atomic.Int64
+
+	err := Block(func(n Nursery) error {
+		for _, p := range pairs {
+			pair := p
+			n.Go(func() error {
+				written, err := io.Copy(pair.Dst, ctxReader{n, pair.Src})
+				total.Add(written)
+				return err
+			})
+		}
+
+		return nil
+	}, append([]BlockOption{WithContext(ctx)}, opts...)...)
+
+	return total.Load(), err
+}
+
+// ctxReader is an io.Reader that fails once its context is done.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+// Read implements io.Reader.
+func (cr ctxReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
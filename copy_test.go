@@ -0,0 +1,60 @@
+package conc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// slowReader is an infinite reader returning a byte per read.
+type slowReader struct{}
+
+func (slowReader) Read(p []byte) (int, error) {
+	time.Sleep(time.Millisecond)
+	p[0] = 'x'
+	return 1, nil
+}
+
+func TestCopyAll(t *testing.T) {
+	t.Run("Parallel", func(t *testing.T) {
+		srcs := []string{"foo", "hello world", strings.Repeat("a", 100000)}
+		dsts := make([]bytes.Buffer, len(srcs))
+		pairs := make([]CopyPair, len(srcs))
+		for i, src := range srcs {
+			pairs[i] = CopyPair{Dst: &dsts[i], Src: strings.NewReader(src)}
+		}
+
+		total, err := CopyAll(context.Background(), pairs, WithMaxGoroutines(2))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != 100014 {
+			t.Fatalf("%v bytes copied instead of 100014", total)
+		}
+		for i, src := range srcs {
+			if dsts[i].String() != src {
+				t.Fatalf("stream %v copied incorrectly", i)
+			}
+		}
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		start := time.Now()
+		_, err := CopyAll(ctx, []CopyPair{
+			{Dst: io.Discard, Src: slowReader{}},
+			{Dst: io.Discard, Src: slowReader{}},
+		})
+		if err != context.Canceled {
+			t.Fatalf("CopyAll returned %v instead of context.Canceled", err)
+		}
+		if time.Since(start) > time.Second {
+			t.Fatal("cancel didn't abort in-progress copies")
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package conc
+
+import (
+	"time"
+)
+
+// cpuTimeSamplingInterval define interval between CPU time samples of
+// WithCPUTimeBudget monitor.
+const cpuTimeSamplingInterval = 5 * time.Millisecond
+
+// monitorCPUTime starts a goroutine that calls n.onCPUBudgetExceeded once if
+// CPU time consumed since call exceeds n.cpuBudget. Returned function stops
+// monitoring goroutine and waits for it to return.
+func (n *nursery) monitorCPUTime() (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		start := cpuTime()
+		ticker := time.NewTicker(cpuTimeSamplingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if cpuTime()-start > n.cpuBudget {
+					n.onCPUBudgetExceeded()
+					return
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+var processStart = time.Now()
+
+// wallTime returns wall-clock time elapsed since process start.
+func wallTime() time.Duration {
+	return time.Since(processStart)
+}
@@ -0,0 +1,13 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package conc
+
+import (
+	"time"
+)
+
+// cpuTime falls back to wall-clock time on platforms where CPU time isn't
+// available.
+func cpuTime() time.Duration {
+	return wallTime()
+}
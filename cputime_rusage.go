@@ -0,0 +1,19 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package conc
+
+import (
+	"syscall"
+	"time"
+)
+
+// cpuTime returns user and system CPU time consumed by the process. Go doesn't
+// expose per-goroutine CPU time so it includes goroutines outside of nursery.
+func cpuTime() time.Duration {
+	var ru syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &ru); err != nil {
+		return wallTime()
+	}
+
+	return time.Duration(ru.Utime.Nano() + ru.Stime.Nano())
+}
@@ -0,0 +1,38 @@
+package conc
+
+import (
+	"fmt"
+)
+
+type depthKey struct{}
+
+// blockDepth define depth of a nursery block and maximum depth of its nested
+// blocks.
+type blockDepth struct {
+	depth int
+	max   int
+}
+
+// checkDepth computes nursery depth from its context and returns an error if
+// it exceeds maximum depth. Depth is stored as a nursery value so nested blocks
+// inherit it.
+func (n *nursery) checkDepth() error {
+	parent, hasParent := n.Context.Value(depthKey{}).(blockDepth)
+	if !hasParent && n.maxDepth <= 0 {
+		return nil
+	}
+
+	d := blockDepth{depth: parent.depth + 1, max: parent.max}
+	if n.maxDepth > 0 {
+		d.max = n.maxDepth
+	}
+	if d.depth > d.max {
+		return fmt.Errorf("%w: block depth %v is above %v", ErrMaxDepthExceeded, d.depth, d.max)
+	}
+
+	if n.values == nil {
+		n.values = make(map[any]any)
+	}
+	n.values[depthKey{}] = d
+	return nil
+}
@@ -0,0 +1,30 @@
+package conc
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMaxDepth(t *testing.T) {
+	// nest opens depth nested blocks and returns deepest error.
+	var nest func(ctx context.Context, depth int, opts ...BlockOption) error
+	nest = func(ctx context.Context, depth int, opts ...BlockOption) error {
+		return Block(func(n Nursery) error {
+			if depth == 1 {
+				return nil
+			}
+			return nest(n, depth-1)
+		}, append([]BlockOption{WithContext(ctx)}, opts...)...)
+	}
+
+	if err := nest(context.Background(), 3, WithMaxDepth(3)); err != nil {
+		t.Fatalf("nesting 3 blocks with max depth 3 returned %v", err)
+	}
+	if err := nest(context.Background(), 4, WithMaxDepth(3)); !errors.Is(err, ErrMaxDepthExceeded) {
+		t.Fatalf("nesting 4 blocks with max depth 3 returned %v instead of ErrMaxDepthExceeded", err)
+	}
+	if err := nest(context.Background(), 10); err != nil {
+		t.Fatalf("nesting blocks without max depth returned %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+package conc
+
+import (
+	"errors"
+)
+
+// ErrHardCapExceeded is the panic value of goroutines spawned while hard
+// goroutine cap is reached.
+var ErrHardCapExceeded = errors.New("hard goroutine cap exceeded")
+
+// ErrMaxDepthExceeded is returned by blocks nested deeper than maximum depth
+// set using WithMaxDepth option.
+var ErrMaxDepthExceeded = errors.New("max nursery depth exceeded")
+
+// ErrMaxQueuedDuration is returned by GoEstimated if estimated
+// duration of queued goroutines would exceed the maximum set using
+// WithMaxQueuedDuration option.
+var ErrMaxQueuedDuration = errors.New("max queued duration exceeded")
+
+// ErrNurseryDraining is the panic value of goroutines spawned in a draining
+// nursery, see StartDraining.
+var ErrNurseryDraining = errors.New("nursery is draining")
+
+// ErrorClass define how a goroutine error affects a nursery.
+type ErrorClass int
+
+const (
+	// Fatal errors are passed to error handler and cancel nursery if it
+	// cancels on error.
+	Fatal ErrorClass = iota
+	// Retryable errors are passed to error handler without canceling nursery.
+	Retryable
+	// Ignore errors are dropped.
+	Ignore
+)
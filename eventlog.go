@@ -0,0 +1,103 @@
+package conc
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventKind define kind of an event recorded in an EventLog.
+type EventKind int
+
+const (
+	EventSpawn EventKind = iota
+	EventStart
+	EventError
+	EventPanic
+	EventFinish
+	EventCancel
+	EventBlockEnd
+)
+
+// String implements fmt.Stringer.
+func (k EventKind) String() string {
+	switch k {
+	case EventSpawn:
+		return "spawn"
+	case EventStart:
+		return "start"
+	case EventError:
+		return "error"
+	case EventPanic:
+		return "panic"
+	case EventFinish:
+		return "finish"
+	case EventCancel:
+		return "cancel"
+	case EventBlockEnd:
+		return "block-end"
+	default:
+		return fmt.Sprintf("EventKind(%d)", int(k))
+	}
+}
+
+// Event is a timestamped nursery event.
+type Event struct {
+	Time time.Time
+	Kind EventKind
+	// Index of the goroutine, it is -1 for nursery events (cancel and end of
+	// block).
+	Index int
+	// Value is the error, panic or cancellation cause of the event, if any.
+	Value any
+}
+
+// String implements fmt.Stringer.
+func (e Event) String() string {
+	s := fmt.Sprintf("%v %v", e.Time.Format("15:04:05.000000"), e.Kind)
+	if e.Index >= 0 {
+		s += fmt.Sprintf(" #%v", e.Index)
+	}
+	if e.Value != nil {
+		s += fmt.Sprintf(": %v", e.Value)
+	}
+	return s
+}
+
+// EventLog records nursery events for debugging, see WithEventLog. Zero value
+// is an empty log ready to use. It is safe for concurrent use.
+type EventLog struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (l *EventLog) record(kind EventKind, index int, value any) {
+	if l == nil {
+		return
+	}
+
+	e := Event{Time: time.Now(), Kind: kind, Index: index, Value: value}
+	l.mu.Lock()
+	l.events = append(l.events, e)
+	l.mu.Unlock()
+}
+
+// Events returns recorded events in order.
+func (l *EventLog) Events() []Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	events := make([]Event, len(l.events))
+	copy(events, l.events)
+	return events
+}
+
+// String returns recorded events, one per line.
+func (l *EventLog) String() string {
+	var b strings.Builder
+	for _, e := range l.Events() {
+		b.WriteString(e.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -0,0 +1,34 @@
+package conc
+
+import (
+	"io"
+	"testing"
+)
+
+func TestEventLog(t *testing.T) {
+	var log EventLog
+	Block(func(n Nursery) error {
+		n.Go(func() error {
+			return io.EOF
+		})
+		return nil
+	}, WithEventLog(&log))
+
+	expected := []EventKind{EventSpawn, EventStart, EventError, EventCancel, EventBlockEnd}
+	events := log.Events()
+	i := 0
+	for _, e := range events {
+		if i < len(expected) && e.Kind == expected[i] {
+			i++
+		}
+	}
+	if i != len(expected) {
+		t.Fatalf("event log doesn't contain %v events in order:\n%v", expected, &log)
+	}
+
+	for _, e := range events {
+		if e.Kind == EventError && (e.Index != 0 || e.Value != io.EOF) {
+			t.Fatalf("error event is %v", e)
+		}
+	}
+}
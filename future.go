@@ -0,0 +1,132 @@
+package conc
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// Result holds value and error returned by a Job.
+type Result[T any] struct {
+	Value T
+	Err   error
+}
+
+// Future holds the result of a Job executed in a nursery goroutine. Future is
+// safe for concurrent use.
+type Future[T any] struct {
+	mu        sync.Mutex
+	done      chan struct{}
+	result    Result[T]
+	listeners []chan Result[T]
+	cancel    context.CancelFunc
+}
+
+// Go executes job in a separate goroutine of nursery n and returns a Future
+// resolved once job returns. Error returned by job is also forwarded to the
+// nursery unless future was canceled. If nursery is canceled before job
+// returns, future is resolved with nursery context error so consumers never
+// block forever. Result of a job that returned is kept even if nursery is
+// canceled concurrently.
+func Go[T any](n Nursery, job Job[T]) *Future[T] {
+	ctx, cancel := context.WithCancel(n)
+	f := &Future[T]{done: make(chan struct{}), cancel: cancel}
+
+	// completed is set once job has returned so a concurrent cancellation
+	// doesn't discard its result.
+	var completed atomic.Bool
+	stop := context.AfterFunc(ctx, func() {
+		if !completed.Load() {
+			f.resolve(Result[T]{Err: ctx.Err()})
+		}
+	})
+
+	n.Go(func() error {
+		defer cancel()
+
+		v, err := job(ctx)
+		completed.Store(true)
+		stop()
+		// No-op if context was canceled before job returned.
+		f.resolve(Result[T]{Value: v, Err: err})
+		if err != nil && ctx.Err() != nil && n.Err() == nil {
+			// Future was canceled.
+			return nil
+		}
+		return err
+	})
+
+	return f
+}
+
+// Cancel cancels context of future job and resolves it with context.Canceled
+// error if it isn't resolved yet. Other goroutines of the nursery aren't
+// affected.
+func (f *Future[T]) Cancel() {
+	f.cancel()
+}
+
+// resolve resolves future with the given result. Only first call has an
+// effect.
+func (f *Future[T]) resolve(r Result[T]) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return
+	default:
+	}
+
+	f.result = r
+	close(f.done)
+	for _, ch := range f.listeners {
+		ch <- r
+		close(ch)
+	}
+	f.listeners = nil
+}
+
+// Get blocks until future is resolved and returns job value and error. If
+// nursery was canceled before job returned, it returns zero value and context
+// error. Get is idempotent: it may be called any number of times from any
+// number of goroutines and always returns the same value and error.
+func (f *Future[T]) Get() (T, error) {
+	<-f.done
+	return f.result.Value, f.result.Err
+}
+
+// Chan returns a channel that delivers future result once and then closes.
+// It is useful to compose futures in select statements.
+func (f *Future[T]) Chan() <-chan Result[T] {
+	ch := make(chan Result[T], 1)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	select {
+	case <-f.done:
+		ch <- f.result
+		close(ch)
+	default:
+		f.listeners = append(f.listeners, ch)
+	}
+
+	return ch
+}
+
+// Await blocks until all futures are resolved and returns their values in
+// order. First non-nil error in futures order is returned.
+func Await[T any](futures ...*Future[T]) ([]T, error) {
+	values := make([]T, len(futures))
+	var firstErr error
+	for i, f := range futures {
+		var err error
+		values[i], err = f.Get()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return values, firstErr
+}
@@ -0,0 +1,230 @@
+package conc
+
+import (
+	"context"
+	"io"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFuture(t *testing.T) {
+	t.Run("Get", func(t *testing.T) {
+		var v int
+		var err error
+		Block(func(n Nursery) error {
+			f := Go(n, func(ctx context.Context) (int, error) {
+				return 42, nil
+			})
+			v, err = f.Get()
+			return nil
+		})
+
+		if v != 42 || err != nil {
+			t.Fatalf("future resolved with (%v, %v) instead of (42, nil)", v, err)
+		}
+	})
+
+	t.Run("Chan/DeliverOnceAndClose", func(t *testing.T) {
+		Block(func(n Nursery) error {
+			f := Go(n, func(ctx context.Context) (int, error) {
+				time.Sleep(time.Millisecond)
+				return 1, io.EOF
+			})
+
+			ch := f.Chan()
+			r, ok := <-ch
+			if !ok {
+				t.Fatal("channel closed before delivering result")
+			}
+			if r.Value != 1 || r.Err != io.EOF {
+				t.Fatalf("channel delivered %+v instead of {1 EOF}", r)
+			}
+			if _, ok := <-ch; ok {
+				t.Fatal("channel delivered more than one result")
+			}
+
+			// Channel created after resolution.
+			r, ok = <-f.Chan()
+			if !ok || r.Value != 1 {
+				t.Fatal("channel created after resolution didn't deliver result")
+			}
+
+			return nil
+		}, WithIgnoreErrors())
+	})
+
+	t.Run("Chan/SelectWithContext", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		Block(func(n Nursery) error {
+			f := Go(n, func(ctx context.Context) (int, error) {
+				<-ctx.Done()
+				return 0, ctx.Err()
+			})
+
+			cancel()
+
+			select {
+			case <-f.Chan():
+			case <-n.Done():
+			case <-time.After(time.Second):
+				t.Fatal("select on future channel and context blocked")
+			}
+
+			return nil
+		}, WithContext(ctx))
+	})
+
+	t.Run("Get/CanceledBlock", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var err error
+		start := time.Now()
+		Block(func(n Nursery) error {
+			f := Go(n, func(ctx context.Context) (int, error) {
+				<-ctx.Done()
+				time.Sleep(100 * time.Millisecond)
+				return 1, nil
+			})
+
+			cancel()
+			_, err = f.Get()
+			if time.Since(start) >= 100*time.Millisecond {
+				t.Fatal("Get didn't return promptly on cancellation")
+			}
+			return nil
+		}, WithContext(ctx))
+
+		if err != context.Canceled {
+			t.Fatalf("Get returned %v instead of context.Canceled", err)
+		}
+	})
+
+	t.Run("Get/NeverStarted", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var err error
+		Block(func(n Nursery) error {
+			n.Go(func() error {
+				<-n.Done()
+				return nil
+			})
+
+			// Limit reached, future routine is never started.
+			cancel()
+			f := Go(n, func(ctx context.Context) (int, error) {
+				return 1, nil
+			})
+			_, err = f.Get()
+			return nil
+		}, WithContext(ctx), WithMaxGoroutines(1))
+
+		if err != context.Canceled {
+			t.Fatalf("Get returned %v instead of context.Canceled", err)
+		}
+	})
+
+	t.Run("Await", func(t *testing.T) {
+		var values []int
+		var err error
+
+		Block(func(n Nursery) error {
+			job := func(v int, err error) Job[int] {
+				return func(ctx context.Context) (int, error) {
+					time.Sleep(time.Duration(3-v) * time.Millisecond)
+					return v, err
+				}
+			}
+
+			values, _ = Await(Go(n, job(1, nil)), Go(n, job(2, nil)), Go(n, job(3, nil)))
+			_, err = Await(Go(n, job(1, nil)), Go(n, job(2, io.EOF)))
+			return nil
+		}, WithIgnoreErrors())
+
+		if !slices.Equal(values, []int{1, 2, 3}) {
+			t.Fatalf("Await returned %v instead of [1 2 3]", values)
+		}
+		if err != io.EOF {
+			t.Fatalf("Await returned %v instead of io.EOF", err)
+		}
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		var canceledErr, siblingErr error
+		jobCanceled := make(chan struct{})
+		sibling := 0
+
+		err := Block(func(n Nursery) error {
+			f := Go(n, func(ctx context.Context) (int, error) {
+				<-ctx.Done()
+				close(jobCanceled)
+				return 0, ctx.Err()
+			})
+			s := Go(n, func(ctx context.Context) (int, error) {
+				time.Sleep(10 * time.Millisecond)
+				return 1, nil
+			})
+
+			f.Cancel()
+			_, canceledErr = f.Get()
+			sibling, siblingErr = s.Get()
+			return nil
+		})
+
+		if err != nil {
+			t.Fatalf("block returned %v instead of nil", err)
+		}
+		select {
+		case <-jobCanceled:
+		default:
+			t.Fatal("canceled future job context wasn't canceled")
+		}
+		if canceledErr != context.Canceled {
+			t.Fatalf("canceled future Get returned %v instead of context.Canceled", canceledErr)
+		}
+		if sibling != 1 || siblingErr != nil {
+			t.Fatalf("sibling future resolved with (%v, %v) instead of (1, nil)", sibling, siblingErr)
+		}
+	})
+
+	t.Run("Get/Concurrent", func(t *testing.T) {
+		type result struct {
+			v   *int
+			err error
+		}
+		results := make([]result, 100)
+
+		Block(func(n Nursery) error {
+			release := make(chan struct{})
+			f := Go(n, func(ctx context.Context) (*int, error) {
+				<-release
+				return new(int), io.EOF
+			})
+
+			getters := make(chan struct{}, len(results))
+			for i := range results {
+				r := &results[i]
+				n.Go(func() error {
+					getters <- struct{}{}
+					r.v, r.err = f.Get()
+					return nil
+				})
+			}
+			for range results {
+				<-getters
+			}
+			close(release)
+			return nil
+		}, WithIgnoreErrors())
+
+		for _, r := range results {
+			if r.v == nil || r.v != results[0].v || r.err != io.EOF {
+				t.Fatalf("concurrent Get returned (%p, %v) instead of (%p, EOF)", r.v, r.err, results[0].v)
+			}
+		}
+	})
+}
@@ -0,0 +1,23 @@
+package conc
+
+import "time"
+
+// waitHealthy blocks until health check succeeds or nursery is canceled.
+func (n *nursery) waitHealthy() {
+	if n.healthCheck == nil || n.healthCheck() {
+		return
+	}
+
+	ticker := time.NewTicker(n.healthPoll)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-n.Done():
+			return
+		case <-ticker.C:
+			if n.healthCheck() {
+				return
+			}
+		}
+	}
+}
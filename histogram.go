@@ -0,0 +1,30 @@
+package conc
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+type durationHistogram struct {
+	buckets  []time.Duration
+	counts   []atomic.Int32
+	reportFn func(counts []int)
+}
+
+// observe tallies duration elapsed since start.
+func (h *durationHistogram) observe(start time.Time) {
+	d := time.Since(start)
+	i := sort.Search(len(h.buckets), func(i int) bool {
+		return d <= h.buckets[i]
+	})
+	h.counts[i].Add(1)
+}
+
+func (h *durationHistogram) report() {
+	counts := make([]int, len(h.counts))
+	for i := range h.counts {
+		counts[i] = int(h.counts[i].Load())
+	}
+	h.reportFn(counts)
+}
@@ -0,0 +1,25 @@
+package conc
+
+// GoroutineInfo describes a nursery goroutine.
+type GoroutineInfo struct {
+	// Index of the goroutine in the nursery, goroutines are indexed in spawn
+	// order starting from 0.
+	Index int
+	// ID of the goroutine, see WithIDAllocator.
+	ID uint64
+	// Name of the goroutine, it is empty for unnamed goroutines.
+	Name string
+	// SpawnSite is file:line of the call that spawned the goroutine, see
+	// WithCaptureSpawnSite.
+	SpawnSite string
+}
+
+// Hooks define functions called on nursery goroutines lifecycle events. Nil
+// hooks are ignored.
+type Hooks struct {
+	// OnStart is called before goroutine routine is executed.
+	OnStart func(GoroutineInfo)
+	// OnFinish is called after goroutine routine returned. It isn't called if
+	// routine panicked.
+	OnFinish func(GoroutineInfo, error)
+}
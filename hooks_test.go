@@ -0,0 +1,76 @@
+package conc
+
+import (
+	"io"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestHooks(t *testing.T) {
+	t.Run("StartAndFinish", func(t *testing.T) {
+		var mu sync.Mutex
+		var started []int
+		var finishErr error
+
+		Block(func(n Nursery) error {
+			n.Go(func() error {
+				return nil
+			})
+			GoNamed(n, "eof", func() error {
+				return io.EOF
+			})
+			return nil
+		}, WithIgnoreErrors(), WithHooks(Hooks{
+			OnStart: func(info GoroutineInfo) {
+				mu.Lock()
+				started = append(started, info.Index)
+				mu.Unlock()
+			},
+			OnFinish: func(info GoroutineInfo, err error) {
+				if info.Name == "eof" {
+					finishErr = err
+				}
+			},
+		}))
+
+		slices.Sort(started)
+		if !slices.Equal(started, []int{0, 1}) {
+			t.Fatalf("started goroutines are %v instead of [0 1]", started)
+		}
+		if finishErr != io.EOF {
+			t.Fatalf("finish hook received %v instead of io.EOF", finishErr)
+		}
+	})
+
+	t.Run("WithIDAllocator", func(t *testing.T) {
+		var next atomic.Uint64
+		next.Store(1000)
+
+		var mu sync.Mutex
+		var ids []uint64
+
+		Block(func(n Nursery) error {
+			for i := 0; i < 3; i++ {
+				n.Go(func() error {
+					return nil
+				})
+			}
+			return nil
+		}, WithIDAllocator(func() uint64 {
+			return next.Add(1)
+		}), WithHooks(Hooks{
+			OnStart: func(info GoroutineInfo) {
+				mu.Lock()
+				ids = append(ids, info.ID)
+				mu.Unlock()
+			},
+		}))
+
+		slices.Sort(ids)
+		if !slices.Equal(ids, []uint64{1001, 1002, 1003}) {
+			t.Fatalf("hooks received ids %v instead of [1001 1002 1003]", ids)
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package conc
+
+import "sync"
+
+// LazyNursery is a nursery whose block starts on first call to Go. No
+// goroutine or context is created for lazy nurseries that end up empty.
+type LazyNursery struct {
+	mu      sync.Mutex
+	opts    []BlockOption
+	n       Nursery
+	waiting chan struct{}
+	done    chan struct{}
+	err     error
+	closed  bool
+}
+
+// Lazy returns a new LazyNursery whose block uses the given options.
+func Lazy(opts ...BlockOption) *LazyNursery {
+	return &LazyNursery{opts: opts}
+}
+
+// Go starts nursery block if it isn't started yet and executes routine in a
+// goroutine of the nursery. Calling Go after Wait panics with ErrNurseryDone.
+func (l *LazyNursery) Go(routine Routine) {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		panic(ErrNurseryDone)
+	}
+	if l.n == nil {
+		l.start()
+	}
+	n := l.n
+	l.mu.Unlock()
+
+	n.Go(routine)
+}
+
+// start starts nursery block. It must be called with l.mu locked.
+func (l *LazyNursery) start() {
+	started := make(chan struct{})
+	l.waiting = make(chan struct{})
+	l.done = make(chan struct{})
+
+	go func() {
+		defer close(l.done)
+		l.err = Block(func(n Nursery) error {
+			l.n = n
+			close(started)
+			select {
+			case <-l.waiting:
+			case <-n.Done():
+			}
+			return nil
+		}, l.opts...)
+	}()
+
+	<-started
+}
+
+// Wait waits for goroutines to return and returns block error. It returns nil
+// immediately if Go was never called.
+func (l *LazyNursery) Wait() error {
+	l.mu.Lock()
+	if l.n == nil {
+		l.closed = true
+		l.mu.Unlock()
+		return nil
+	}
+	if !l.closed {
+		l.closed = true
+		close(l.waiting)
+	}
+	l.mu.Unlock()
+
+	<-l.done
+	return l.err
+}
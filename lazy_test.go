@@ -0,0 +1,49 @@
+package conc
+
+import (
+	"io"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLazy(t *testing.T) {
+	t.Run("Untouched", func(t *testing.T) {
+		goroutines := runtime.NumGoroutine()
+		l := Lazy()
+		if l.n != nil || runtime.NumGoroutine() != goroutines {
+			t.Fatal("lazy nursery started before first Go")
+		}
+
+		start := time.Now()
+		if err := l.Wait(); err != nil {
+			t.Fatalf("Wait returned %v instead of nil", err)
+		}
+		if time.Since(start) > 10*time.Millisecond {
+			t.Fatal("Wait on untouched lazy nursery didn't return immediately")
+		}
+	})
+
+	t.Run("Wait", func(t *testing.T) {
+		var done atomic.Int32
+		l := Lazy()
+		for i := 0; i < 3; i++ {
+			l.Go(func() error {
+				time.Sleep(time.Millisecond)
+				done.Add(1)
+				return nil
+			})
+		}
+		l.Go(func() error {
+			return io.EOF
+		})
+
+		if err := l.Wait(); err != io.EOF {
+			t.Fatalf("Wait returned %v instead of io.EOF", err)
+		}
+		if done.Load() != 3 {
+			t.Fatalf("Wait returned before %v goroutine(s) completed", 3-done.Load())
+		}
+	})
+}
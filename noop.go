@@ -0,0 +1,53 @@
+package conc
+
+import (
+	"sync"
+	"time"
+)
+
+// NoopNursery is a Nursery implementation for unit testing code that takes a
+// Nursery parameter without real concurrency. Routines are executed
+// synchronously by the calling goroutine, their errors are recorded and
+// returned by Errors. Panics aren't recovered. Its context is never canceled
+// and has no deadline nor values. Zero value is ready to use. Package
+// functions spawning a goroutine, such as GoCtx, execute routines through Go.
+type NoopNursery struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+// Deadline implements context.Context.
+func (nn *NoopNursery) Deadline() (deadline time.Time, ok bool) {
+	return time.Time{}, false
+}
+
+// Done implements context.Context. Returned channel is never closed.
+func (nn *NoopNursery) Done() <-chan struct{} {
+	return nil
+}
+
+// Err implements context.Context. It always returns nil.
+func (nn *NoopNursery) Err() error {
+	return nil
+}
+
+// Value implements context.Context. It always returns nil.
+func (nn *NoopNursery) Value(any) any {
+	return nil
+}
+
+// Errors returns errors returned by executed routines in execution order.
+func (nn *NoopNursery) Errors() []error {
+	nn.mu.Lock()
+	defer nn.mu.Unlock()
+	return append([]error(nil), nn.errs...)
+}
+
+// Go implements Nursery.
+func (nn *NoopNursery) Go(routine Routine) {
+	if err := routine(); err != nil {
+		nn.mu.Lock()
+		nn.errs = append(nn.errs, err)
+		nn.mu.Unlock()
+	}
+}
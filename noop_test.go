@@ -0,0 +1,113 @@
+package conc
+
+import (
+	"context"
+	"io"
+	"testing"
+)
+
+// Compile time check.
+var _ Nursery = &NoopNursery{}
+
+// spawnAll is an example of code depending on Nursery interface.
+func spawnAll(n Nursery, values []int, out []int) {
+	for i, v := range values {
+		index := i
+		value := v
+		n.Go(func() error {
+			out[index] = value * 2
+			return nil
+		})
+	}
+}
+
+func TestNoopNursery(t *testing.T) {
+	t.Run("Synchronous", func(t *testing.T) {
+		var n NoopNursery
+		executed := false
+		n.Go(func() error {
+			executed = true
+			return nil
+		})
+
+		if !executed {
+			t.Fatal("routine not executed before Go returned")
+		}
+	})
+
+	t.Run("Interface", func(t *testing.T) {
+		var n NoopNursery
+		out := make([]int, 3)
+		spawnAll(&n, []int{1, 2, 3}, out)
+
+		if out[0] != 2 || out[1] != 4 || out[2] != 6 {
+			t.Fatalf("unexpected output: %v", out)
+		}
+		if n.Done() != nil || n.Err() != nil {
+			t.Fatal("noop nursery context is done")
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		var n NoopNursery
+		n.Go(func() error { return io.EOF })
+		n.Go(func() error { return nil })
+		n.Go(func() error { return io.ErrUnexpectedEOF })
+
+		errs := n.Errors()
+		if len(errs) != 2 || errs[0] != io.EOF || errs[1] != io.ErrUnexpectedEOF {
+			t.Fatalf("unexpected errors: %v", errs)
+		}
+	})
+
+	t.Run("PackageFunctions", func(t *testing.T) {
+		var n NoopNursery
+		executed := 0
+		GoCtx(&n, func(ctx context.Context) error {
+			if ctx != Nursery(&n) {
+				t.Error("routine context isn't nursery")
+			}
+			executed++
+			return nil
+		})
+		GoPool(&n, "unknown", func() error {
+			executed++
+			return nil
+		})
+		if !TryGo(&n, func() error {
+			executed++
+			return io.EOF
+		}) {
+			t.Fatal("TryGo failed")
+		}
+
+		if index := GoIndexed(&n, func() error {
+			executed++
+			return nil
+		}); index != -1 {
+			t.Fatalf("GoIndexed returned index %v instead of -1", index)
+		}
+		Collect(&n, func(ctx context.Context) (int, error) {
+			executed++
+			return 0, nil
+		})
+
+		if executed != 5 {
+			t.Fatalf("%v routines executed instead of 5", executed)
+		}
+		if errs := n.Errors(); len(errs) != 1 || errs[0] != io.EOF {
+			t.Fatalf("unexpected errors: %v", errs)
+		}
+	})
+
+	t.Run("Accessors", func(t *testing.T) {
+		var n NoopNursery
+		defer func() {
+			p := recover()
+			if p != "conc: StatsOf requires a nursery created by Block" {
+				t.Fatalf("unexpected panic: %v", p)
+			}
+		}()
+		StatsOf(&n)
+	})
+}
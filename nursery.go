@@ -2,9 +2,14 @@ package conc
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
 	"runtime/debug"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // Routine define a function executed in its own goroutine.
@@ -13,7 +18,14 @@ type Routine = func() error
 // Nursery is a supervisor that executes goroutines and manages their lifecycle.
 // It embeds a context.Context to provide cancellation and deadlines to all
 // spawned goroutines. When the nursery's context is canceled, all goroutines
-// are signaled to stop via the context cancellation.
+// are signaled to stop via the context cancellation. Value method of nursery
+// returns block-scoped values set using WithValue option before looking up
+// context values.
+//
+// Nurseries created by Block are extended by package functions taking a
+// Nursery, such as GoCtx or GoPool. Given another Nursery implementation,
+// functions spawning a goroutine fall back to its Go method and other
+// functions panic.
 type Nursery interface {
 	context.Context
 
@@ -27,78 +39,890 @@ type nursery struct {
 	onError       func(error)
 	errors        chan error
 	limiter       limiter
-	goRoutine     chan Routine
+	goRoutine     chan task
 	routinesCount atomic.Int32
+	taskTimeout   time.Duration
+
+	maxGoroutines int
+	activeCount   atomic.Int32
+	peakCount     atomic.Int32
+	onUnderused   func(peak, limit int)
+	minObserved   int
+	onMinObserved func(peak int)
+
+	tasksCount atomic.Int32
+	nameGen    func(index int) string
+
+	noRecover bool
+
+	pendingMu   sync.Mutex
+	pending     pendingQueue
+	dispatching bool
+	// claimed is the number of pending tasks that will be popped by a
+	// dispatched placeholder task.
+	claimed int
+
+	onDrainStart func(active int)
+
+	afterFuncsMu sync.Mutex
+	afterFuncs   []func() bool
+
+	cpuBudget           time.Duration
+	onCPUBudgetExceeded func()
+
+	values map[any]any
+
+	cancelOnError bool
+	classifier    func(error) ErrorClass
+
+	closed     atomic.Bool
+	drainMu    sync.Mutex
+	drained    chan struct{}
+	collectors sync.Map
+
+	trackStacks bool
+	trackedMu   sync.Mutex
+	tracked     map[uint64]task
+	dumpWriter  io.Writer
+
+	ended chan struct{}
+
+	hardCap  int
+	capCount atomic.Int32
+
+	categoryTimeouts map[string]time.Duration
+
+	panicAsError bool
+	formatPanic  func(GoroutinePanic) error
+	onPanic      func(GoroutinePanic)
+
+	firstPanicAsError bool
+	firstPanicOnce    sync.Once
+	firstPanic        error
+
+	histogram *durationHistogram
+
+	maxDepth int
+
+	abandonAfter time.Duration
+	onAbandon    func(indices []int)
+	abandoned    chan struct{}
+	// running holds indices of running goroutines, it is nil unless running
+	// goroutines are tracked.
+	runningMu sync.Mutex
+	running   map[int]struct{}
+
+	hooks   Hooks
+	idAlloc func() uint64
+
+	name           string
+	queuedCount    atomic.Int32
+	completedCount atomic.Int64
+	failedCount    atomic.Int64
+	panickedCount  atomic.Int64
+
+	emitLimiter RateLimiter
+
+	maxQueuedDuration time.Duration
+	queuedDuration    atomic.Int64
+
+	logger           *slog.Logger
+	autoErrorLogging bool
+
+	closersMu sync.Mutex
+	closers   []closer
+
+	summary *summary
+
+	healthCheck func() bool
+	healthPoll  time.Duration
+
+	slowest *slowest
+
+	middlewares []func(next Routine) Routine
+
+	pools map[string]limiter
+
+	sharedLimiter RateLimiter
+
+	bytes *weightedSemaphore
+
+	draining atomic.Bool
+
+	tracer Tracer
+
+	eventLog *EventLog
+
+	captureSpawnSite bool
+
+	throttle *errorRateThrottle
+}
+
+// task define a routine scheduled on a nursery goroutine.
+type task struct {
+	routine Routine
+	index   int
+	id      uint64
+	name    string
+	// spawnSite is file:line of the call that spawned the task.
+	spawnSite string
+	// throttled tasks hold a slot of error rate throttle.
+	throttled bool
+	// internal tasks (e.g. block function) aren't accounted in nursery
+	// statistics.
+	internal bool
+	// onDrop, if set, is called when task is dropped without being executed.
+	onDrop func()
+	// resolve, if set, returns the actual task to execute once a goroutine is
+	// available. It is used by placeholders of pending priority tasks.
+	resolve func() task
+}
+
+func (t *task) info() GoroutineInfo {
+	return GoroutineInfo{Index: t.index, ID: t.id, Name: t.name, SpawnSite: t.spawnSite}
 }
 
 func newNursery() *nursery {
 	n := &nursery{
-		Context:   nil,
-		cancel:    nil,
-		onError:   nil,
-		errors:    make(chan error),
+		Context: nil,
+		cancel:  nil,
+		onError: nil,
+		errors:  make(chan error),
+		formatPanic: func(p GoroutinePanic) error {
+			return panicError{p}
+		},
 		limiter:   nil,
-		goRoutine: make(chan func() error),
+		goRoutine: make(chan task),
+		ended:     make(chan struct{}),
 	}
 
 	return n
 }
 
-func catchPanics(routineDone chan<- error) {
-	if err := recover(); err != nil {
-		routineDone <- GoroutinePanic{
-			Value: err,
-			Stack: string(debug.Stack()),
+func (n *nursery) catchPanics(current *task) {
+	if v := recover(); v != nil {
+		p := GoroutinePanic{
+			Value:     v,
+			Stack:     string(debug.Stack()),
+			Name:      current.name,
+			Index:     current.index,
+			ID:        current.id,
+			SpawnSite: current.spawnSite,
+		}
+
+		if !current.internal {
+			n.panickedCount.Add(1)
+			n.eventLog.record(EventPanic, current.index, p)
 		}
+
+		// Panicking goroutine exits, release its slot.
+		if n.limiter != nil {
+			<-n.limiter
+		}
+
+		if n.onPanic != nil {
+			n.onPanic(p)
+		}
+
+		if n.summary != nil {
+			n.summary.add(n.formatPanic(p), true)
+			n.report(nil)
+			return
+		}
+
+		if n.firstPanicAsError {
+			n.firstPanicOnce.Do(func() {
+				n.firstPanic = n.formatPanic(p)
+			})
+			n.report(nil)
+			return
+		}
+
+		if !n.panicAsError {
+			n.report(p)
+			return
+		}
+
+		err := n.formatPanic(p)
+		n.handleError(err)
+		n.report(err)
+	}
+}
+
+// report reports end of a routine to block event loop. It returns false if
+// block abandoned its goroutines.
+func (n *nursery) report(err error) bool {
+	select {
+	case n.errors <- err:
+		return true
+	case <-n.abandoned:
+		n.releaseRoutine()
+		return false
+	}
+}
+
+// releaseRoutine decrements routines count outside of event loop. Once block
+// abandoned its goroutines, ended is closed when last one has returned or was
+// dropped.
+func (n *nursery) releaseRoutine() {
+	if n.routinesCount.Add(-1) != 0 {
+		return
+	}
+	select {
+	case <-n.abandoned:
+		close(n.ended)
+	default:
 	}
 }
 
 // Go implements Nursery.
 func (n *nursery) Go(routine func() error) {
+	n.goTask(n.newTask(routine, ""))
+}
+
+// GoIndexed is the same as n.Go but returns index assigned to the goroutine.
+// Indices are unique and increase monotonically in spawn order. It returns -1
+// if n wasn't created by Block.
+func GoIndexed(n Nursery, routine Routine) int {
+	impl, ok := n.(*nursery)
+	if !ok {
+		n.Go(routine)
+		return -1
+	}
+	t := impl.newTask(routine, "")
+	impl.goTask(t)
+	return t.index
+}
+
+// GoEstimated is the same as n.Go but accounts estimated duration of routine
+// until it starts. If sum of estimated durations of queued goroutines would
+// exceed maximum set using WithMaxQueuedDuration, routine isn't spawned and
+// an error wrapping ErrMaxQueuedDuration is returned.
+func GoEstimated(n Nursery, estimated time.Duration, routine Routine) error {
+	impl, ok := n.(*nursery)
+	if !ok {
+		n.Go(routine)
+		return nil
+	}
+	return impl.goEstimated(estimated, routine)
+}
+
+func (n *nursery) goEstimated(estimated time.Duration, routine Routine) error {
+	if n.draining.Load() {
+		return ErrNurseryDraining
+	}
+	if n.maxQueuedDuration > 0 {
+		for {
+			queued := n.queuedDuration.Load()
+			if queued+int64(estimated) > int64(n.maxQueuedDuration) {
+				return fmt.Errorf("%w: %v queued", ErrMaxQueuedDuration, time.Duration(queued))
+			}
+			if n.queuedDuration.CompareAndSwap(queued, queued+int64(estimated)) {
+				break
+			}
+		}
+	}
+
+	// Estimate is accounted until routine starts or is dropped.
+	release := func() { n.queuedDuration.Add(-int64(estimated)) }
+	n.goReleasing(func() error {
+		release()
+		return routine()
+	}, release)
+	return nil
+}
+
+// GoPool is the same as n.Go but routine is gated by concurrency limit of the
+// given named pool (see WithNamedPools). It blocks until pool has capacity or
+// nursery is canceled, routine isn't executed in the latter case. It panics
+// if pool doesn't exist.
+func GoPool(n Nursery, pool string, routine Routine) {
+	impl, ok := n.(*nursery)
+	if !ok {
+		n.Go(routine)
+		return
+	}
+	impl.goPool(pool, routine)
+}
+
+func (n *nursery) goPool(pool string, routine Routine) {
+	sem, ok := n.pools[pool]
+	if !ok {
+		panic(fmt.Sprintf("conc: unknown pool %q", pool))
+	}
+
+	select {
+	case sem <- struct{}{}:
+	case <-n.Done():
+		return
+	}
+
+	release := func() { <-sem }
+	n.goReleasing(func() error {
+		defer release()
+		return routine()
+	}, release)
+}
+
+// goReleasing is the same as Go except release is called if routine isn't
+// executed because spawning panicked or task was dropped. routine must call
+// release itself otherwise.
+func (n *nursery) goReleasing(routine Routine, release func()) {
+	var t task
+	func() {
+		defer func() {
+			if p := recover(); p != nil {
+				release()
+				panic(p)
+			}
+		}()
+		t = n.newTask(routine, "")
+	}()
+
+	t.onDrop = release
+	n.goTask(t)
+}
+
+// StartDraining switches nursery n to draining mode and returns immediately.
+// Goroutines spawned afterward are rejected: n.Go and its variants panic with
+// ErrNurseryDraining, TryGo fails and GoEstimated returns
+// ErrNurseryDraining. Running goroutines complete normally and nursery
+// context isn't canceled, block ends once they have returned.
+func StartDraining(n Nursery) {
+	mustNursery(n, "StartDraining").draining.Store(true)
+}
+
+// GoNamed is the same as n.Go except goroutine is named. Name is used for
+// diagnostic purposes (e.g. in GoroutinePanic).
+func GoNamed(n Nursery, name string, routine Routine) {
+	impl, ok := n.(*nursery)
+	if !ok {
+		n.Go(routine)
+		return
+	}
+	impl.goNamed(name, routine)
+}
+
+func (n *nursery) goNamed(name string, routine Routine) {
+	n.goTask(n.newTask(routine, name))
+}
+
+func (n *nursery) newTask(routine Routine, name string) task {
+	if n.closed.Load() {
+		panic(ErrNurseryDone)
+	}
+	if n.draining.Load() {
+		panic(ErrNurseryDraining)
+	}
+	n.waitHealthy()
+	if n.sharedLimiter != nil {
+		// Error is ignored, task is dropped on cancellation.
+		_ = n.sharedLimiter.Wait(n)
+	}
+	if !n.acquireCap() {
+		panic(fmt.Errorf("%w: more than %v goroutines", ErrHardCapExceeded, n.hardCap))
+	}
+	throttled := n.throttle != nil && n.throttle.sem.acquire(n, 1)
+
+	t := n.buildTask(routine, name)
+	t.throttled = throttled
+	return t
+}
+
+func (n *nursery) buildTask(routine Routine, name string) task {
+	t := task{
+		routine: routine,
+		index:   int(n.tasksCount.Add(1) - 1),
+		name:    name,
+	}
+	if n.captureSpawnSite {
+		t.spawnSite = spawnSite()
+	}
+	n.queuedCount.Add(1)
+	n.eventLog.record(EventSpawn, t.index, nil)
+	for i := len(n.middlewares) - 1; i >= 0; i-- {
+		t.routine = n.middlewares[i](t.routine)
+	}
+	if n.idAlloc != nil {
+		t.id = n.idAlloc()
+	} else {
+		t.id = uint64(t.index)
+	}
+	if t.name == "" && n.nameGen != nil {
+		t.name = n.nameGen(t.index)
+	}
+
+	return t
+}
+
+func (n *nursery) goTask(t task) {
 	n.routinesCount.Add(1)
+	n.admit(t)
 	if n.limiter == nil {
 		select {
-		case n.goRoutine <- routine:
+		case n.goRoutine <- t:
 			// Successfully reused a goroutine.
 		default:
 			// No goroutine available, spawn a new one.
-			n.goNew(routine)
+			n.goNew(t)
 		}
 	} else {
 		select {
 		case n.limiter <- struct{}{}:
 			// We are below our limit.
-			n.goNew(routine)
+			n.goNew(t)
 		case <-n.Done():
 			// Context canceled.
-			n.routinesCount.Add(-1)
-		case n.goRoutine <- routine:
+			n.drop(t)
+		case n.goRoutine <- t:
 			// Successfully reused a goroutine.
 		}
 	}
 }
 
-func (n *nursery) goNew(routine Routine) {
+// admit drops t and panics with ErrNurseryDone if nursery was closed while t
+// was being spawned. It must be called once t is accounted in routinesCount so
+// either closeAndWait waits for t or t is rejected.
+func (n *nursery) admit(t task) {
+	if t.resolve != nil || !n.closed.Load() {
+		return
+	}
+
+	n.drop(t)
+	if n.routinesCount.Load() == 1 {
+		// Wake up closeAndWait that observed t.
+		n.notifyDrained()
+	}
+	panic(ErrNurseryDone)
+}
+
+// TryGo executes provided [Routine] in a separate goroutine of nursery n only
+// if it doesn't block because goroutines limit is reached. It reports whether
+// routine was started.
+func TryGo(n Nursery, routine Routine) bool {
+	impl, ok := n.(*nursery)
+	if !ok {
+		n.Go(routine)
+		return true
+	}
+	return impl.tryGo(routine)
+}
+
+func (n *nursery) tryGo(routine Routine) bool {
+	if n.closed.Load() {
+		panic(ErrNurseryDone)
+	}
+	if n.draining.Load() || (n.healthCheck != nil && !n.healthCheck()) {
+		return false
+	}
+	if !n.acquireCap() {
+		return false
+	}
+	if n.throttle != nil && !n.throttle.sem.tryAcquire(1) {
+		n.releaseCap()
+		return false
+	}
+
+	t := n.buildTask(routine, "")
+	t.throttled = n.throttle != nil
+	if n.limiter == nil {
+		n.goTask(t)
+		return true
+	}
+
+	n.routinesCount.Add(1)
+	n.admit(t)
+	select {
+	case n.limiter <- struct{}{}:
+		// We are below our limit.
+		n.goNew(t)
+		return true
+	case n.goRoutine <- t:
+		// Successfully reused a goroutine.
+		return true
+	default:
+		n.drop(t)
+		return false
+	}
+}
+
+func (n *nursery) goNew(t task) {
 	go func() {
-		defer catchPanics(n.errors)
-		for r := range n.goRoutine {
-			err := r()
-			if err != nil {
-				n.onError(err)
+		var current task
+		if !n.noRecover {
+			defer n.catchPanics(&current)
+		}
+		for {
+			var ok bool
+			select {
+			case current, ok = <-n.goRoutine:
+				if !ok {
+					return
+				}
+			case <-n.abandoned:
+				return
+			}
+			if current.resolve != nil {
+				current = current.resolve()
+			}
+
+			if !n.report(n.run(current)) {
+				return
 			}
-			n.errors <- err
 		}
 	}()
 
+	if t.internal {
+		// Block function is the first task, spawned goroutine receives it.
+		n.goRoutine <- t
+		return
+	}
+
 	select {
 	case <-n.Done():
 		// Context canceled.
-		n.routinesCount.Add(-1)
-	case n.goRoutine <- routine:
+		n.drop(t)
+	case n.goRoutine <- t:
 		// routine forwarded.
 	}
 }
 
+// drop drops a task that won't be executed.
+func (n *nursery) drop(t task) {
+	if t.resolve != nil {
+		t = t.resolve()
+	}
+	if t.onDrop != nil {
+		t.onDrop()
+	}
+	n.releaseRoutine()
+	if !t.internal {
+		n.queuedCount.Add(-1)
+		n.releaseCap()
+		if t.throttled {
+			n.throttle.sem.release(1)
+		}
+	}
+}
+
+// acquireCap reserves a goroutine under hard goroutine cap and reports whether
+// it succeeded.
+func (n *nursery) acquireCap() bool {
+	if n.hardCap <= 0 {
+		return true
+	}
+
+	for {
+		count := n.capCount.Load()
+		if count >= int32(n.hardCap) {
+			return false
+		}
+		if n.capCount.CompareAndSwap(count, count+1) {
+			return true
+		}
+	}
+}
+
+func (n *nursery) releaseCap() {
+	if n.hardCap > 0 {
+		n.capCount.Add(-1)
+	}
+}
+
+// run executes task in current goroutine.
+func (n *nursery) run(t task) error {
+	if t.internal {
+		return t.routine()
+	}
+
+	n.queuedCount.Add(-1)
+	n.trackPeak(n.activeCount.Add(1))
+	defer n.activeCount.Add(-1)
+	defer n.releaseCap()
+	if t.throttled {
+		defer n.throttle.sem.release(1)
+	}
+
+	if n.trackStacks {
+		untrack := n.track(t)
+		defer untrack()
+	}
+
+	if n.running != nil {
+		n.runningMu.Lock()
+		n.running[t.index] = struct{}{}
+		n.runningMu.Unlock()
+		defer func() {
+			n.runningMu.Lock()
+			delete(n.running, t.index)
+			n.runningMu.Unlock()
+		}()
+	}
+
+	n.eventLog.record(EventStart, t.index, nil)
+	if n.hooks.OnStart != nil {
+		n.hooks.OnStart(t.info())
+	}
+
+	logErrors := n.autoErrorLogging && n.logger != nil
+	var start time.Time
+	if logErrors || n.slowest != nil || n.histogram != nil {
+		start = time.Now()
+	}
+
+	err := t.routine()
+	if n.histogram != nil {
+		// Panicked goroutines aren't accounted.
+		n.histogram.observe(start)
+	}
+	if err != nil {
+		n.failedCount.Add(1)
+		n.eventLog.record(EventError, t.index, err)
+		if logErrors {
+			n.logger.Error("goroutine failed",
+				slog.Int("index", t.index),
+				slog.String("name", t.name),
+				slog.Duration("duration", time.Since(start)),
+				slog.Any("error", err),
+			)
+		}
+		n.handleError(err)
+	} else {
+		n.completedCount.Add(1)
+	}
+
+	if n.throttle != nil {
+		n.throttle.observe(err != nil)
+	}
+
+	if n.slowest != nil {
+		n.slowest.observe(GoroutineOutcome{
+			Index:    t.index,
+			Name:     t.name,
+			Duration: time.Since(start),
+			Err:      err,
+		})
+	}
+
+	n.eventLog.record(EventFinish, t.index, nil)
+	if n.hooks.OnFinish != nil {
+		n.hooks.OnFinish(t.info(), err)
+	}
+	return err
+}
+
+// handleError handles a non-nil goroutine error according to its class.
+func (n *nursery) handleError(err error) {
+	class := Fatal
+	if n.classifier != nil {
+		class = n.classifier(err)
+	}
+
+	switch class {
+	case Ignore:
+	case Retryable:
+		n.onError(err)
+	default:
+		if n.cancelOnError {
+			n.cancel()
+		}
+		n.onError(err)
+	}
+}
+
+// reportError handles err as if it was returned by a goroutine of nursery n.
+// It lets long-lived goroutines report errors without returning.
+func reportError(n Nursery, err error) {
+	if impl, ok := n.(*nursery); ok {
+		impl.handleError(err)
+		return
+	}
+	n.Go(func() error { return err })
+}
+
+// mustNursery returns n as a nursery created by Block. It panics otherwise,
+// fn is the name of the calling function.
+func mustNursery(n Nursery, fn string) *nursery {
+	impl, ok := n.(*nursery)
+	if !ok {
+		panic("conc: " + fn + " requires a nursery created by Block")
+	}
+	return impl
+}
+
+// goFinally is the same as n.Go except finally is called once routine has
+// returned or, if n is a nursery created by Block, once routine task is
+// dropped without being executed. It lets helpers close channels owned by a
+// routine even if nursery is canceled before it starts.
+func goFinally(n Nursery, routine Routine, finally func()) {
+	wrapped := func() error {
+		defer finally()
+		return routine()
+	}
+
+	if impl, ok := n.(*nursery); ok {
+		impl.goReleasing(wrapped, finally)
+		return
+	}
+	n.Go(wrapped)
+}
+
+func (n *nursery) trackPeak(active int32) {
+	for {
+		peak := n.peakCount.Load()
+		if active <= peak || n.peakCount.CompareAndSwap(peak, active) {
+			return
+		}
+	}
+}
+
+// GoCtx is the same as n.Go except provided function receives a context
+// derived from nursery. If a default task timeout is configured, context has
+// a deadline.
+func GoCtx(n Nursery, routine func(context.Context) error) {
+	impl, ok := n.(*nursery)
+	if !ok {
+		n.Go(func() error { return routine(n) })
+		return
+	}
+	impl.goCtx("", impl.taskTimeout, routine)
+}
+
+// GoWithTimeout is the same as GoCtx except provided function context times
+// out after the given duration. It takes precedence over default task
+// timeout.
+func GoWithTimeout(n Nursery, timeout time.Duration, routine func(context.Context) error) {
+	impl, ok := n.(*nursery)
+	if !ok {
+		n.Go(func() error {
+			ctx, cancel := context.WithTimeout(n, timeout)
+			defer cancel()
+			return routine(ctx)
+		})
+		return
+	}
+	impl.goCtx("", timeout, routine)
+}
+
+// GoNamedCtx is the same as GoCtx except goroutine is named. If a category
+// timeout is configured for this name, it overrides default task timeout.
+func GoNamedCtx(n Nursery, name string, routine func(context.Context) error) {
+	impl, ok := n.(*nursery)
+	if !ok {
+		GoCtx(n, routine)
+		return
+	}
+	timeout, hasCategory := impl.categoryTimeouts[name]
+	if !hasCategory {
+		timeout = impl.taskTimeout
+	}
+	impl.goCtx(name, timeout, routine)
+}
+
+// GoWithContext is the same as GoCtx except routine context is also canceled
+// when the given extra context is canceled.
+func GoWithContext(n Nursery, extra context.Context, routine func(context.Context) error) {
+	GoCtx(n, func(ctx context.Context) error {
+		ctx, cancel := context.WithCancelCause(ctx)
+		defer cancel(nil)
+
+		stop := context.AfterFunc(extra, func() {
+			cancel(context.Cause(extra))
+		})
+		defer stop()
+
+		return routine(ctx)
+	})
+}
+
+// goCtx spawns a routine receiving a context that times out after the given
+// duration if it is positive.
+func (n *nursery) goCtx(name string, timeout time.Duration, routine func(context.Context) error) {
+	if timeout <= 0 {
+		n.goNamed(name, func() error {
+			return routine(n)
+		})
+		return
+	}
+
+	n.goNamed(name, func() error {
+		ctx, cancel := context.WithTimeout(n, timeout)
+		defer cancel()
+		return routine(ctx)
+	})
+}
+
+// AfterFunc arranges to call fn in its own goroutine after context of
+// nursery n is canceled. Calling returned stop function stops the association
+// of fn with nursery context, see context.AfterFunc for more details. fn is
+// never called if block ends without being canceled.
+func AfterFunc(n Nursery, fn func()) (stop func() bool) {
+	impl, ok := n.(*nursery)
+	if !ok {
+		return context.AfterFunc(n, fn)
+	}
+	return impl.afterFunc(fn)
+}
+
+func (n *nursery) afterFunc(fn func()) func() bool {
+	stop := context.AfterFunc(n, fn)
+
+	n.afterFuncsMu.Lock()
+	n.afterFuncs = append(n.afterFuncs, stop)
+	n.afterFuncsMu.Unlock()
+
+	return stop
+}
+
+func (n *nursery) stopAfterFuncs() {
+	n.afterFuncsMu.Lock()
+	defer n.afterFuncsMu.Unlock()
+
+	for _, stop := range n.afterFuncs {
+		stop()
+	}
+	n.afterFuncs = nil
+}
+
+// Available returns how many more goroutines of nursery n can start
+// immediately without blocking, that is, maximum number of goroutines minus
+// running ones. It returns math.MaxInt if number of goroutines is unlimited.
+func Available(n Nursery) int {
+	impl := mustNursery(n, "Available")
+	if impl.limiter == nil {
+		return math.MaxInt
+	}
+	return max(impl.maxGoroutines-int(impl.activeCount.Load()), 0)
+}
+
+// IsRunning returns whether goroutine of nursery n with the given index is
+// currently executing. Goroutines are indexed in spawn order starting from 0.
+// Running goroutines must be tracked using WithRunningTracking option,
+// otherwise it always returns false.
+func IsRunning(n Nursery, index int) bool {
+	impl := mustNursery(n, "IsRunning")
+	impl.runningMu.Lock()
+	defer impl.runningMu.Unlock()
+	_, ok := impl.running[index]
+	return ok
+}
+
+// Ended returns a channel that is closed once block of nursery n has ended
+// and all goroutines have returned. Unlike Done, it is never closed while
+// goroutines are still running.
+func Ended(n Nursery) <-chan struct{} {
+	return mustNursery(n, "Ended").ended
+}
+
+// Value implements context.Context.
+func (n *nursery) Value(key any) any {
+	if v, ok := n.values[key]; ok {
+		return v
+	}
+
+	return n.Context.Value(key)
+}
+
 func (n *nursery) sendRoutine(routine Routine) {
 
 }
@@ -109,45 +933,135 @@ func (n *nursery) sendRoutine(routine Routine) {
 // canceled and panic is immediately forwarded without waiting for other
 // goroutines to handle context cancellation. Error returned by block closure
 // always trigger a context cancellation and is returned if it occurs before a
-// default goroutine error handler is called.
+// default goroutine error handler is called. Block closure is executed even if
+// context is already canceled, goroutines it spawns may then be dropped.
 func Block(block func(n Nursery) error, opts ...BlockOption) (err error) {
 	n := newNursery()
 	for _, opt := range opts {
 		opt(n)
 	}
+	abandoned := false
+	defer func() {
+		if !abandoned {
+			close(n.ended)
+		}
+	}()
+	// Close registered resources once context is canceled if block panics,
+	// closers were already closed otherwise.
+	defer func() { _ = n.closeResources() }()
 
 	// Default context.
 	if n.Context == nil {
 		n.Context, n.cancel = context.WithCancel(context.Background())
 	}
 	defer n.cancel()
+	// Stop after funcs before canceling context at end of block.
+	defer n.stopAfterFuncs()
+
+	if err := n.checkDepth(); err != nil {
+		return err
+	}
+	// Inherit shared rate limiter of parent blocks.
+	n.sharedLimiter, _ = n.Value(sharedLimiterKey{}).(RateLimiter)
+
+	if n.eventLog != nil {
+		// Record cancellation synchronously when nursery cancels itself and
+		// asynchronously for parent cancellation.
+		var canceled sync.Once
+		recordCancel := func() {
+			canceled.Do(func() {
+				n.eventLog.record(EventCancel, -1, context.Cause(n))
+			})
+		}
+		cancel := n.cancel
+		n.cancel = func() {
+			cancel()
+			recordCancel()
+		}
+		n.afterFunc(recordCancel)
+	}
+
+	if n.dumpWriter != nil {
+		var dumping sync.WaitGroup
+		dumping.Add(1)
+		stop := n.afterFunc(func() {
+			defer dumping.Done()
+			if n.Err() == context.DeadlineExceeded {
+				n.writeStacks(n.dumpWriter)
+			}
+		})
+		// Block doesn't return while dump is being written.
+		defer func() {
+			if stop() {
+				dumping.Done()
+			}
+			dumping.Wait()
+		}()
+	}
+
+	if n.onCPUBudgetExceeded != nil {
+		defer n.monitorCPUTime()()
+	}
 
 	// Default error handler.
 	once := sync.Once{}
+	recordErr := func(e error) {
+		once.Do(func() {
+			err = e
+		})
+	}
 	if n.onError == nil {
-		n.onError = func(e error) {
-			once.Do(func() {
-				n.cancel()
-				err = e
-			})
-		}
+		n.onError = recordErr
+		n.cancelOnError = true
 	}
 
-	// Start block.
-	n.Go(func() error {
-		e := block(n)
-		if e != nil {
-			once.Do(func() {
+	// Start block. Unlike other tasks, block function is executed even if
+	// nursery is already canceled.
+	n.routinesCount.Add(1)
+	if n.limiter != nil {
+		n.limiter <- struct{}{}
+	}
+	n.goNew(task{
+		routine: func() error {
+			e := block(n)
+			if e != nil {
 				n.cancel()
-				err = e
-			})
-		}
-		return nil
+				recordErr(e)
+			}
+			if n.onDrainStart != nil {
+				// Exclude block goroutine.
+				n.onDrainStart(int(n.routinesCount.Load()) - 1)
+			}
+			return nil
+		},
+		internal: true,
 	})
 
+	// Abandonment timer starts on cancellation.
+	var canceled <-chan struct{}
+	var abandonTimer <-chan time.Time
+	if n.abandonAfter > 0 {
+		canceled = n.Done()
+	}
+
 	// Event loop.
+loop:
 	for {
-		e := <-n.errors
+		var e error
+		select {
+		case e = <-n.errors:
+		case <-canceled:
+			canceled = nil
+			abandonTimer = time.After(n.abandonAfter)
+			continue
+		case <-abandonTimer:
+			// Prevent abandoned goroutines from setting returned error.
+			once.Do(func() {})
+			abandoned = true
+			n.abandon()
+			break loop
+		}
+
 		if panicValue, isPanic := e.(GoroutinePanic); isPanic {
 			panic(panicValue)
 		}
@@ -157,6 +1071,47 @@ func Block(block func(n Nursery) error, opts ...BlockOption) (err error) {
 			close(n.errors)
 			break
 		}
+		if count == 1 {
+			// Only block goroutine remains.
+			n.notifyDrained()
+		}
+	}
+
+	if e := n.closeResources(); e != nil {
+		recordErr(e)
+	}
+	n.eventLog.record(EventBlockEnd, -1, nil)
+
+	if n.histogram != nil {
+		n.histogram.report()
+	}
+
+	if n.slowest != nil {
+		n.slowest.report()
+	}
+
+	if n.onUnderused != nil {
+		peak := int(n.peakCount.Load())
+		if peak*2 < n.maxGoroutines {
+			n.onUnderused(peak, n.maxGoroutines)
+		}
+	}
+
+	if n.onMinObserved != nil {
+		peak := int(n.peakCount.Load())
+		if peak < n.minObserved {
+			n.onMinObserved(peak)
+		}
+	}
+
+	if n.firstPanic != nil {
+		return n.firstPanic
+	}
+
+	if n.summary != nil {
+		if e := n.summary.err(int(n.tasksCount.Load()), err); e != nil {
+			return e
+		}
 	}
 
 	return err
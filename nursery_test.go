@@ -1,9 +1,20 @@
 package conc
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"log/slog"
+	"math"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -217,4 +228,1315 @@ func TestNursery(t *testing.T) {
 			t.Fatalf("error handler provided error isn't io.EOF")
 		}
 	})
+
+	t.Run("WithDefaultTaskTimeout", func(t *testing.T) {
+		var defaultDeadline, customDeadline time.Time
+		var hasDefault, hasCustom bool
+
+		start := time.Now()
+		Block(func(n Nursery) error {
+			GoCtx(n, func(ctx context.Context) error {
+				defaultDeadline, hasDefault = ctx.Deadline()
+				return nil
+			})
+			GoWithTimeout(n, time.Millisecond, func(ctx context.Context) error {
+				customDeadline, hasCustom = ctx.Deadline()
+				return nil
+			})
+			return nil
+		}, WithDefaultTaskTimeout(time.Second))
+
+		if !hasDefault {
+			t.Fatal("GoCtx routine context has no deadline")
+		}
+		if d := defaultDeadline.Sub(start); d < time.Second || d > 1100*time.Millisecond {
+			t.Fatalf("GoCtx routine deadline is %v after start instead of ~1s", d)
+		}
+		if !hasCustom {
+			t.Fatal("GoWithTimeout routine context has no deadline")
+		}
+		if !customDeadline.Before(defaultDeadline) {
+			t.Fatal("GoWithTimeout didn't take precedence over default task timeout")
+		}
+	})
+
+	t.Run("WithUnderutilizationWarning", func(t *testing.T) {
+		t.Run("Underused", func(t *testing.T) {
+			called := false
+			var peak, limit int
+			Block(func(n Nursery) error {
+				n.Go(func() error {
+					return nil
+				})
+				return nil
+			}, WithMaxGoroutines(10), WithUnderutilizationWarning(func(p, l int) {
+				called = true
+				peak, limit = p, l
+			}))
+
+			if !called {
+				t.Fatal("underutilization warning not emitted")
+			}
+			if peak != 1 || limit != 10 {
+				t.Fatalf("warning called with (%v, %v) instead of (1, 10)", peak, limit)
+			}
+		})
+
+		t.Run("OddLimit", func(t *testing.T) {
+			called := false
+			Block(func(n Nursery) error {
+				n.Go(func() error {
+					return nil
+				})
+				return nil
+			}, WithMaxGoroutines(3), WithUnderutilizationWarning(func(p, l int) {
+				called = true
+			}))
+
+			if !called {
+				t.Fatal("underutilization warning not emitted with peak 1 and limit 3")
+			}
+		})
+
+		t.Run("Saturated", func(t *testing.T) {
+			called := false
+			Block(func(n Nursery) error {
+				for i := 0; i < 20; i++ {
+					n.Go(func() error {
+						time.Sleep(5 * time.Millisecond)
+						return nil
+					})
+				}
+				return nil
+			}, WithMaxGoroutines(10), WithUnderutilizationWarning(func(p, l int) {
+				called = true
+			}))
+
+			if called {
+				t.Fatal("underutilization warning emitted while limit is saturated")
+			}
+		})
+	})
+
+	t.Run("GoNamed", func(t *testing.T) {
+		var panicValue any
+
+		func() {
+			defer func() {
+				panicValue = recover()
+			}()
+
+			Block(func(n Nursery) error {
+				GoNamed(n, "foo-worker", func() error {
+					panic("foo")
+				})
+				return nil
+			})
+		}()
+
+		if name := panicValue.(GoroutinePanic).Name; name != "foo-worker" {
+			t.Fatalf("goroutine panic name is %q instead of foo-worker", name)
+		}
+	})
+
+	t.Run("WithNameGenerator", func(t *testing.T) {
+		var panicValue any
+
+		func() {
+			defer func() {
+				panicValue = recover()
+			}()
+
+			Block(func(n Nursery) error {
+				n.Go(func() error {
+					return nil
+				})
+				n.Go(func() error {
+					panic("foo")
+				})
+				return nil
+			}, WithNameGenerator(func(index int) string {
+				return fmt.Sprintf("nursery-test-%v", index)
+			}))
+		}()
+
+		if name := panicValue.(GoroutinePanic).Name; name != "nursery-test-1" {
+			t.Fatalf("goroutine panic name is %q instead of nursery-test-1", name)
+		}
+	})
+
+	t.Run("WithoutPanicRecovery", func(t *testing.T) {
+		if os.Getenv("CONC_TEST_NATIVE_PANIC") == "1" {
+			Block(func(n Nursery) error {
+				n.Go(func() error {
+					panic("native panic")
+				})
+				return nil
+			}, WithoutPanicRecovery())
+			return
+		}
+
+		cmd := exec.Command(os.Args[0], "-test.run=^TestNursery/WithoutPanicRecovery$")
+		cmd.Env = append(os.Environ(), "CONC_TEST_NATIVE_PANIC=1")
+		output, err := cmd.CombinedOutput()
+		if err == nil {
+			t.Fatal("panicking goroutine didn't crash process")
+		}
+		if !strings.Contains(string(output), "panic: native panic") {
+			t.Fatalf("process didn't crash with native panic:\n%s", output)
+		}
+		if strings.Contains(string(output), "catchPanics") {
+			t.Fatalf("panic was recovered before crash:\n%s", output)
+		}
+	})
+
+	t.Run("WithOnDrainStart", func(t *testing.T) {
+		calls := 0
+		active := 0
+		Block(func(n Nursery) error {
+			for i := 0; i < 3; i++ {
+				n.Go(func() error {
+					time.Sleep(10 * time.Millisecond)
+					return nil
+				})
+			}
+			n.Go(func() error {
+				return nil
+			})
+			time.Sleep(time.Millisecond)
+			return nil
+		}, WithOnDrainStart(func(a int) {
+			calls++
+			active = a
+		}))
+
+		if calls != 1 {
+			t.Fatalf("drain start callback called %v time(s) instead of 1 time", calls)
+		}
+		if active != 3 {
+			t.Fatalf("drain start callback received %v active goroutines instead of 3", active)
+		}
+	})
+
+	t.Run("AfterFunc", func(t *testing.T) {
+		t.Run("Canceled", func(t *testing.T) {
+			called := make(chan struct{})
+			Block(func(n Nursery) error {
+				AfterFunc(n, func() {
+					close(called)
+				})
+				n.Go(func() error {
+					return io.EOF
+				})
+				return nil
+			})
+
+			select {
+			case <-called:
+			case <-time.After(time.Second):
+				t.Fatal("after func not called on cancellation")
+			}
+		})
+
+		t.Run("NotCanceled", func(t *testing.T) {
+			var called atomic.Bool
+			Block(func(n Nursery) error {
+				AfterFunc(n, func() {
+					called.Store(true)
+				})
+				return nil
+			})
+
+			time.Sleep(10 * time.Millisecond)
+			if called.Load() {
+				t.Fatal("after func called while block ended without cancellation")
+			}
+		})
+	})
+
+	t.Run("WithCPUTimeBudget", func(t *testing.T) {
+		if testing.Short() {
+			t.Skip("CPU time is measured process-wide")
+		}
+
+		t.Run("CPUHeavy", func(t *testing.T) {
+			var exceeded atomic.Bool
+			Block(func(n Nursery) error {
+				n.Go(func() error {
+					// Spin until budget is exceeded, CPU time may be shared
+					// with other processes.
+					start := time.Now()
+					for !exceeded.Load() && time.Since(start) < 5*time.Second {
+					}
+					return nil
+				})
+				return nil
+			}, WithCPUTimeBudget(50*time.Millisecond, func() {
+				exceeded.Store(true)
+			}))
+
+			if !exceeded.Load() {
+				t.Fatal("CPU time budget exceeded callback not called")
+			}
+		})
+
+		t.Run("Idle", func(t *testing.T) {
+			var exceeded atomic.Bool
+			// Budget is large enough to absorb CPU time of other goroutines of
+			// the process.
+			Block(func(n Nursery) error {
+				n.Go(func() error {
+					time.Sleep(20 * time.Millisecond)
+					return nil
+				})
+				return nil
+			}, WithCPUTimeBudget(500*time.Millisecond, func() {
+				exceeded.Store(true)
+			}))
+
+			if exceeded.Load() {
+				t.Fatal("CPU time budget exceeded callback called for an idle block")
+			}
+		})
+	})
+
+	t.Run("WithValue", func(t *testing.T) {
+		type counterKey struct{}
+		counter := &atomic.Int32{}
+
+		Block(func(n Nursery) error {
+			for i := 0; i < 10; i++ {
+				GoCtx(n, func(ctx context.Context) error {
+					ctx.Value(counterKey{}).(*atomic.Int32).Add(1)
+					return nil
+				})
+			}
+			return nil
+		}, WithValue(counterKey{}, counter), WithDefaultTaskTimeout(time.Second))
+
+		if counter.Load() != 10 {
+			t.Fatalf("shared value counter is %v instead of 10", counter.Load())
+		}
+	})
+
+	t.Run("WithErrorClassifier", func(t *testing.T) {
+		errRetryable := errors.New("retryable")
+		errFatal := errors.New("fatal")
+		errIgnored := errors.New("ignored")
+		classifier := func(err error) ErrorClass {
+			switch err {
+			case errRetryable:
+				return Retryable
+			case errIgnored:
+				return Ignore
+			default:
+				return Fatal
+			}
+		}
+
+		// run spawns a goroutine returning err and reports whether its
+		// sibling was canceled.
+		run := func(err error, opts ...BlockOption) (canceled bool, handled []error) {
+			var mu sync.Mutex
+			opts = append(opts, WithErrorClassifier(classifier), WithCancelOnError(),
+				WithErrorHandler(func(err error) {
+					mu.Lock()
+					handled = append(handled, err)
+					mu.Unlock()
+				}))
+
+			Block(func(n Nursery) error {
+				n.Go(func() error {
+					select {
+					case <-n.Done():
+						canceled = true
+					case <-time.After(20 * time.Millisecond):
+					}
+					return nil
+				})
+				n.Go(func() error {
+					return err
+				})
+				return nil
+			}, opts...)
+			return
+		}
+
+		if canceled, handled := run(errRetryable); canceled || len(handled) != 1 {
+			t.Fatalf("retryable error: canceled=%v handled=%v", canceled, handled)
+		}
+		if canceled, handled := run(errFatal); !canceled || len(handled) != 1 {
+			t.Fatalf("fatal error: canceled=%v handled=%v", canceled, handled)
+		}
+		if canceled, handled := run(errIgnored); canceled || len(handled) != 0 {
+			t.Fatalf("ignored error: canceled=%v handled=%v", canceled, handled)
+		}
+	})
+
+	t.Run("WithTimeoutGoroutineDump", func(t *testing.T) {
+		// Dump is written before block returns, buffer isn't synchronized.
+		var dump bytes.Buffer
+		Block(func(n Nursery) error {
+			n.Go(func() error {
+				return nil
+			})
+			n.Go(func() error {
+				timeoutDumpStuckRoutine()
+				return nil
+			})
+			return nil
+		}, WithTimeout(10*time.Millisecond), WithTimeoutGoroutineDump(&dump))
+
+		if !strings.Contains(dump.String(), "timeoutDumpStuckRoutine") {
+			t.Fatalf("goroutine dump doesn't contain stuck goroutine:\n%v", dump.String())
+		}
+		if strings.Count(dump.String(), "nursery goroutine") != 1 {
+			t.Fatalf("goroutine dump doesn't contain exactly one goroutine:\n%v", dump.String())
+		}
+	})
+
+	t.Run("Ended", func(t *testing.T) {
+		var lastDone atomic.Bool
+		endedAfterLast := make(chan bool, 1)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		Block(func(n Nursery) error {
+			go func() {
+				<-Ended(n)
+				endedAfterLast <- lastDone.Load()
+			}()
+
+			n.Go(func() error {
+				<-n.Done()
+				time.Sleep(10 * time.Millisecond)
+				lastDone.Store(true)
+				return nil
+			})
+
+			cancel()
+			select {
+			case <-Ended(n):
+				t.Fatal("ended channel closed while goroutines are running")
+			default:
+			}
+			return nil
+		}, WithContext(ctx))
+
+		if !<-endedAfterLast {
+			t.Fatal("ended channel closed before last goroutine completed")
+		}
+	})
+
+	t.Run("WithHardGoroutineCap", func(t *testing.T) {
+		var panicValue any
+		tryGoStarted := true
+
+		Block(func(n Nursery) error {
+			release := make(chan struct{})
+			for i := 0; i < 3; i++ {
+				n.Go(func() error {
+					<-release
+					return nil
+				})
+			}
+
+			tryGoStarted = TryGo(n, func() error {
+				return nil
+			})
+			func() {
+				defer func() {
+					panicValue = recover()
+				}()
+				n.Go(func() error {
+					return nil
+				})
+			}()
+
+			close(release)
+			return nil
+		}, WithHardGoroutineCap(3))
+
+		if tryGoStarted {
+			t.Fatal("TryGo started a goroutine above hard cap")
+		}
+		err, isErr := panicValue.(error)
+		if !isErr || !errors.Is(err, ErrHardCapExceeded) {
+			t.Fatalf("spawning above hard cap panicked with %v instead of ErrHardCapExceeded", panicValue)
+		}
+		if !strings.Contains(err.Error(), "3 goroutines") {
+			t.Fatalf("hard cap panic error isn't descriptive: %v", err)
+		}
+	})
+
+	t.Run("ActiveStacks", func(t *testing.T) {
+		var stacks map[int]string
+		Block(func(n Nursery) error {
+			release := make(chan struct{})
+			parked := make(chan struct{})
+			n.Go(func() error {
+				return nil
+			})
+			n.Go(func() error {
+				activeStacksParkedRoutine(parked, release)
+				return nil
+			})
+
+			<-parked
+			stacks = ActiveStacks(n)
+			close(release)
+			return nil
+		}, WithStackTracking())
+
+		if !strings.Contains(stacks[1], "activeStacksParkedRoutine") {
+			t.Fatalf("active stacks doesn't contain parked goroutine: %v", stacks)
+		}
+	})
+
+	t.Run("WithCategoryTimeouts", func(t *testing.T) {
+		var dbDeadline time.Time
+		var dbHasDeadline, otherHasDeadline bool
+
+		start := time.Now()
+		Block(func(n Nursery) error {
+			GoNamedCtx(n, "db", func(ctx context.Context) error {
+				dbDeadline, dbHasDeadline = ctx.Deadline()
+				return nil
+			})
+			GoNamedCtx(n, "http", func(ctx context.Context) error {
+				_, otherHasDeadline = ctx.Deadline()
+				return nil
+			})
+			return nil
+		}, WithCategoryTimeouts(map[string]time.Duration{"db": time.Second}))
+
+		if !dbHasDeadline {
+			t.Fatal("db goroutine context has no deadline")
+		}
+		if d := dbDeadline.Sub(start); d < time.Second || d > 1100*time.Millisecond {
+			t.Fatalf("db goroutine deadline is %v after start instead of ~1s", d)
+		}
+		if otherHasDeadline {
+			t.Fatal("unlisted goroutine context has a deadline")
+		}
+	})
+
+	t.Run("WithPanicAsError", func(t *testing.T) {
+		t.Run("DefaultFormatter", func(t *testing.T) {
+			err := Block(func(n Nursery) error {
+				n.Go(func() error {
+					panic(io.EOF)
+				})
+				return nil
+			}, WithPanicAsError())
+
+			if err == nil || err.Error() != "goroutine panicked: EOF" {
+				t.Fatalf("block returned %v instead of panic error", err)
+			}
+			var gp GoroutinePanic
+			if !errors.As(err, &gp) || !errors.Is(err, io.EOF) {
+				t.Fatal("panic error doesn't wrap GoroutinePanic and panic value")
+			}
+		})
+
+		t.Run("CustomFormatter", func(t *testing.T) {
+			errCustom := errors.New("custom")
+			err := Block(func(n Nursery) error {
+				n.Go(func() error {
+					panic("foo")
+				})
+				return nil
+			}, WithPanicAsError(), WithPanicErrorFormatter(func(p GoroutinePanic) error {
+				return fmt.Errorf("%w: %v", errCustom, p.Value)
+			}))
+
+			if !errors.Is(err, errCustom) || err.Error() != "custom: foo" {
+				t.Fatalf("block returned %v instead of custom formatter error", err)
+			}
+		})
+	})
+
+	t.Run("GoWithContext", func(t *testing.T) {
+		// run spawns a goroutine bound to an extra context and cancels
+		// nursery or extra context. It returns goroutine context error.
+		run := func(cancelExtra bool) error {
+			var routineErr error
+			extra, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			Block(func(n Nursery) error {
+				started := make(chan struct{})
+				GoWithContext(n, extra, func(ctx context.Context) error {
+					close(started)
+					select {
+					case <-ctx.Done():
+						routineErr = ctx.Err()
+					case <-time.After(time.Second):
+					}
+					return nil
+				})
+
+				<-started
+				if cancelExtra {
+					cancel()
+				} else {
+					n.(*nursery).cancel()
+				}
+				return nil
+			})
+
+			return routineErr
+		}
+
+		if err := run(true); err != context.Canceled {
+			t.Fatalf("canceling extra context didn't cancel routine: %v", err)
+		}
+		if err := run(false); err != context.Canceled {
+			t.Fatalf("canceling nursery didn't cancel routine: %v", err)
+		}
+	})
+
+	t.Run("WithDurationHistogram", func(t *testing.T) {
+		var counts []int
+		Block(func(n Nursery) error {
+			for _, d := range []time.Duration{0, 0, 20 * time.Millisecond, 60 * time.Millisecond} {
+				n.Go(func() error {
+					time.Sleep(d)
+					return nil
+				})
+			}
+			n.Go(func() error {
+				panic("foo")
+			})
+			return nil
+		}, WithPanicAsError(), WithIgnoreErrors(), WithDurationHistogram([]time.Duration{10 * time.Millisecond, 50 * time.Millisecond}, func(c []int) {
+			counts = c
+		}))
+
+		if !slices.Equal(counts, []int{2, 1, 1}) {
+			t.Fatalf("histogram counts are %v instead of [2 1 1]", counts)
+		}
+	})
+
+	t.Run("WithAbandonAfter", func(t *testing.T) {
+		var abandoned []int
+		release := make(chan struct{})
+		var closed atomic.Bool
+		closer := closerFunc(func() error {
+			closed.Store(true)
+			return nil
+		})
+		ended := make(chan (<-chan struct{}), 1)
+
+		start := time.Now()
+		Block(func(n Nursery) error {
+			ended <- Ended(n)
+			RegisterCloser(n, 0, closer)
+			n.Go(func() error {
+				<-n.Done()
+				return nil
+			})
+			n.Go(func() error {
+				// Stuck goroutine ignoring cancellation.
+				<-release
+				return nil
+			})
+			return nil
+		}, WithTimeout(10*time.Millisecond), WithAbandonAfter(10*time.Millisecond, func(indices []int) {
+			abandoned = indices
+		}))
+
+		if time.Since(start) > 100*time.Millisecond {
+			t.Fatal("block didn't abandon stuck goroutine")
+		}
+		if !slices.Equal(abandoned, []int{1}) {
+			t.Fatalf("abandoned goroutines are %v instead of [1]", abandoned)
+		}
+		if !closed.Load() {
+			t.Fatal("registered closer not closed on abandon")
+		}
+
+		endedCh := <-ended
+		select {
+		case <-endedCh:
+			t.Fatal("ended channel closed while abandoned goroutine is running")
+		default:
+		}
+		close(release)
+		select {
+		case <-endedCh:
+		case <-time.After(time.Second):
+			t.Fatal("ended channel not closed once abandoned goroutine returned")
+		}
+	})
+
+	t.Run("WithAbandonAfterDropped", func(t *testing.T) {
+		release := make(chan struct{})
+		var impl *nursery
+		Block(func(n Nursery) error {
+			impl = n.(*nursery)
+			n.Go(func() error {
+				// Stuck goroutine ignoring cancellation.
+				<-release
+				return nil
+			})
+			return nil
+		}, WithMaxGoroutines(2), WithTimeout(10*time.Millisecond), WithAbandonAfter(10*time.Millisecond, nil))
+
+		// Task is dropped as nursery is canceled and goroutines limit is
+		// reached. Abandoned goroutine returns before task is dropped.
+		goFinally(impl, func() error { return nil }, func() {
+			close(release)
+			for impl.routinesCount.Load() > 1 {
+				time.Sleep(time.Millisecond)
+			}
+		})
+
+		select {
+		case <-Ended(impl):
+		case <-time.After(time.Second):
+			t.Fatal("ended channel not closed once last routine was dropped")
+		}
+	})
+
+	t.Run("WithFirstPanicAsError", func(t *testing.T) {
+		var mu sync.Mutex
+		var panics []any
+		first := make(chan struct{})
+		siblingDone := false
+
+		err := Block(func(n Nursery) error {
+			n.Go(func() error {
+				defer close(first)
+				panic("first")
+			})
+			n.Go(func() error {
+				<-first
+				time.Sleep(time.Millisecond)
+				panic("second")
+			})
+			n.Go(func() error {
+				<-first
+				time.Sleep(10 * time.Millisecond)
+				siblingDone = n.Err() == nil
+				return nil
+			})
+			return nil
+		}, WithFirstPanicAsError(), WithPanicHandler(func(p GoroutinePanic) {
+			mu.Lock()
+			panics = append(panics, p.Value)
+			mu.Unlock()
+		}))
+
+		var gp GoroutinePanic
+		if !errors.As(err, &gp) || gp.Value != "first" {
+			t.Fatalf("block returned %v instead of first panic error", err)
+		}
+		if !slices.Equal(panics, []any{"first", "second"}) {
+			t.Fatalf("panic handler received %v instead of [first second]", panics)
+		}
+		if !siblingDone {
+			t.Fatal("sibling goroutine didn't continue after panics")
+		}
+	})
+
+	t.Run("Available", func(t *testing.T) {
+		Block(func(n Nursery) error {
+			if Available(n) != math.MaxInt {
+				t.Fatalf("unlimited nursery has %v available slots instead of math.MaxInt", Available(n))
+			}
+			return nil
+		})
+
+		Block(func(n Nursery) error {
+			if Available(n) != 2 {
+				t.Fatalf("nursery has %v available slots instead of 2", Available(n))
+			}
+
+			started := make(chan struct{})
+			release := make(chan struct{})
+			n.Go(func() error {
+				close(started)
+				<-release
+				return nil
+			})
+			<-started
+			if Available(n) != 1 {
+				t.Fatalf("nursery has %v available slots instead of 1", Available(n))
+			}
+
+			close(release)
+			deadline := time.Now().Add(time.Second)
+			for Available(n) != 2 {
+				if time.Now().After(deadline) {
+					t.Fatal("available slots didn't recover after goroutine returned")
+				}
+				time.Sleep(time.Millisecond)
+			}
+			return nil
+		}, WithMaxGoroutines(2))
+	})
+
+	t.Run("IsRunning", func(t *testing.T) {
+		Block(func(n Nursery) error {
+			started := make(chan struct{})
+			n.Go(func() error {
+				close(started)
+				time.Sleep(10 * time.Millisecond)
+				return nil
+			})
+			<-started
+			if !IsRunning(n, 0) {
+				t.Fatal("sleeping goroutine isn't running")
+			}
+			if IsRunning(n, 1) {
+				t.Fatal("goroutine never spawned is running")
+			}
+
+			deadline := time.Now().Add(time.Second)
+			for IsRunning(n, 0) {
+				if time.Now().After(deadline) {
+					t.Fatal("goroutine still running after it completed")
+				}
+				time.Sleep(time.Millisecond)
+			}
+			return nil
+		}, WithRunningTracking())
+	})
+
+	t.Run("GoIndexed", func(t *testing.T) {
+		var mu sync.Mutex
+		hookIndices := make(map[int]bool)
+		var indices []int
+		var panicIndex int
+
+		err := Block(func(n Nursery) error {
+			for i := 0; i < 3; i++ {
+				indices = append(indices, GoIndexed(n, func() error {
+					return nil
+				}))
+			}
+			panicIndex = GoIndexed(n, func() error {
+				panic("foo")
+			})
+			return nil
+		}, WithFirstPanicAsError(), WithHooks(Hooks{
+			OnStart: func(info GoroutineInfo) {
+				mu.Lock()
+				hookIndices[info.Index] = true
+				mu.Unlock()
+			},
+		}))
+
+		if !slices.Equal(indices, []int{0, 1, 2}) {
+			t.Fatalf("GoIndexed returned %v instead of [0 1 2]", indices)
+		}
+		for _, i := range indices {
+			if !hookIndices[i] {
+				t.Fatalf("index %v not reported in hooks", i)
+			}
+		}
+		var gp GoroutinePanic
+		if !errors.As(err, &gp) || gp.Index != panicIndex || panicIndex != 3 {
+			t.Fatalf("panic reported index %v instead of %v", gp.Index, panicIndex)
+		}
+	})
+
+	t.Run("GoEstimated", func(t *testing.T) {
+		t.Run("Rejected", func(t *testing.T) {
+			errs := make(chan error, 3)
+			var impl *nursery
+
+			Block(func(n Nursery) error {
+				impl = n.(*nursery)
+				release := make(chan struct{})
+				n.Go(func() error {
+					// Hold the only goroutine slot so tasks are queued.
+					<-release
+					return nil
+				})
+
+				for i := 0; i < 3; i++ {
+					go func() {
+						errs <- GoEstimated(n, 40*time.Millisecond, func() error { return nil })
+					}()
+				}
+
+				// Rejected task returns immediately.
+				if err := <-errs; !errors.Is(err, ErrMaxQueuedDuration) {
+					t.Errorf("task exceeding estimated duration cap accepted: %v", err)
+				}
+				close(release)
+				return nil
+			}, WithMaxGoroutines(1), WithMaxQueuedDuration(100*time.Millisecond))
+
+			if err1, err2 := <-errs, <-errs; err1 != nil || err2 != nil {
+				t.Fatalf("tasks within estimated duration cap rejected: %v, %v", err1, err2)
+			}
+			if queued := impl.queuedDuration.Load(); queued != 0 {
+				t.Fatalf("%v queued after tasks started", time.Duration(queued))
+			}
+		})
+
+		t.Run("Dropped", func(t *testing.T) {
+			var impl *nursery
+			Block(func(n Nursery) error {
+				impl = n.(*nursery)
+				n.Go(func() error {
+					<-n.Done()
+					return nil
+				})
+
+				spawned := make(chan error)
+				go func() {
+					// Dropped once nursery is canceled.
+					spawned <- GoEstimated(n, 40*time.Millisecond, func() error {
+						t.Error("dropped routine executed")
+						return nil
+					})
+				}()
+
+				for impl.queuedDuration.Load() == 0 {
+					time.Sleep(time.Millisecond)
+				}
+				impl.cancel()
+				if err := <-spawned; err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return nil
+			}, WithMaxGoroutines(1), WithMaxQueuedDuration(100*time.Millisecond))
+
+			if queued := impl.queuedDuration.Load(); queued != 0 {
+				t.Fatalf("%v queued after task was dropped", time.Duration(queued))
+			}
+		})
+	})
+
+	t.Run("WithAutoErrorLogging", func(t *testing.T) {
+		handler := &captureHandler{}
+		Block(func(n Nursery) error {
+			GoNamed(n, "fetch", func() error {
+				return io.EOF
+			})
+			return nil
+		}, WithIgnoreErrors(), WithLogger(slog.New(handler)), WithAutoErrorLogging())
+
+		if len(handler.records) != 1 {
+			t.Fatalf("%v log record(s) instead of 1", len(handler.records))
+		}
+		r := handler.records[0]
+		if r.Level != slog.LevelError {
+			t.Fatalf("record level is %v instead of error", r.Level)
+		}
+		attrs := make(map[string]slog.Value)
+		r.Attrs(func(a slog.Attr) bool {
+			attrs[a.Key] = a.Value
+			return true
+		})
+		if attrs["index"].Int64() != 0 || attrs["name"].String() != "fetch" {
+			t.Fatalf("record has wrong goroutine fields: %v", attrs)
+		}
+		if _, ok := attrs["duration"]; !ok {
+			t.Fatal("record has no duration field")
+		}
+		if attrs["error"].Any() != io.EOF {
+			t.Fatalf("record error is %v instead of io.EOF", attrs["error"])
+		}
+	})
+
+	t.Run("WithHealthGate", func(t *testing.T) {
+		var healthy atomic.Bool
+		var ran atomic.Bool
+
+		Block(func(n Nursery) error {
+			spawned := make(chan struct{})
+			go func() {
+				n.Go(func() error {
+					ran.Store(true)
+					return nil
+				})
+				close(spawned)
+			}()
+
+			select {
+			case <-spawned:
+				t.Fatal("goroutine spawned while unhealthy")
+			case <-time.After(10 * time.Millisecond):
+			}
+			if TryGo(n, func() error { return nil }) {
+				t.Fatal("TryGo succeeded while unhealthy")
+			}
+
+			healthy.Store(true)
+			select {
+			case <-spawned:
+			case <-time.After(time.Second):
+				t.Fatal("goroutine not spawned once healthy")
+			}
+			return nil
+		}, WithHealthGate(healthy.Load, time.Millisecond))
+
+		if !ran.Load() {
+			t.Fatal("gated goroutine didn't run")
+		}
+	})
+
+	t.Run("WithHealthGate/Cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		start := time.Now()
+		Block(func(n Nursery) error {
+			n.Go(func() error { return nil })
+			return nil
+		}, WithContext(ctx), WithHealthGate(func() bool { return false }, time.Millisecond))
+		if time.Since(start) > time.Second {
+			t.Fatal("cancel didn't unblock gated spawn")
+		}
+	})
+
+	t.Run("WithTopSlowest", func(t *testing.T) {
+		var outcomes []GoroutineOutcome
+		Block(func(n Nursery) error {
+			for _, ms := range []int{1, 30, 5, 20, 10} {
+				d := time.Duration(ms) * time.Millisecond
+				GoNamed(n, d.String(), func() error {
+					time.Sleep(d)
+					return nil
+				})
+			}
+			return nil
+		}, WithTopSlowest(2, func(o []GoroutineOutcome) {
+			outcomes = o
+		}))
+
+		if len(outcomes) != 2 {
+			t.Fatalf("%v outcome(s) reported instead of 2", len(outcomes))
+		}
+		if outcomes[0].Name != "30ms" || outcomes[0].Index != 1 || outcomes[1].Name != "20ms" || outcomes[1].Index != 3 {
+			t.Fatalf("reported outcomes %+v aren't the 2 slowest", outcomes)
+		}
+	})
+
+	t.Run("WithTaskMiddleware", func(t *testing.T) {
+		t.Run("InjectError", func(t *testing.T) {
+			err := Block(func(n Nursery) error {
+				n.Go(func() error {
+					return nil
+				})
+				return nil
+			}, WithTaskMiddleware(func(next Routine) Routine {
+				return func() error {
+					_ = next()
+					return io.EOF
+				}
+			}))
+
+			if err != io.EOF {
+				t.Fatalf("block returned %v instead of injected error", err)
+			}
+		})
+
+		t.Run("InjectLatency", func(t *testing.T) {
+			var order []string
+			latency := func(next Routine) Routine {
+				return func() error {
+					time.Sleep(10 * time.Millisecond)
+					return next()
+				}
+			}
+			trace := func(name string) func(Routine) Routine {
+				return func(next Routine) Routine {
+					return func() error {
+						order = append(order, name)
+						return next()
+					}
+				}
+			}
+
+			start := time.Now()
+			Block(func(n Nursery) error {
+				n.Go(func() error {
+					return nil
+				})
+				return nil
+			}, WithTaskMiddleware(trace("outer")), WithTaskMiddleware(latency), WithTaskMiddleware(trace("inner")))
+
+			if time.Since(start) < 10*time.Millisecond {
+				t.Fatal("latency middleware didn't delay task completion")
+			}
+			if !slices.Equal(order, []string{"outer", "inner"}) {
+				t.Fatalf("middlewares executed in order %v instead of [outer inner]", order)
+			}
+		})
+	})
+
+	t.Run("WithNamedPools", func(t *testing.T) {
+		limits := map[string]int{"db": 2, "http": 5}
+		var mu sync.Mutex
+		active := make(map[string]int)
+		peak := make(map[string]int)
+
+		Block(func(n Nursery) error {
+			// Spawn goroutines of each pool concurrently.
+			for pool := range limits {
+				p := pool
+				n.Go(func() error {
+					for i := 0; i < 20; i++ {
+						GoPool(n, p, func() error {
+							mu.Lock()
+							active[p]++
+							peak[p] = max(peak[p], active[p])
+							mu.Unlock()
+
+							time.Sleep(5 * time.Millisecond)
+
+							mu.Lock()
+							active[p]--
+							mu.Unlock()
+							return nil
+						})
+					}
+					return nil
+				})
+			}
+			return nil
+		}, WithNamedPools(limits))
+
+		for pool, limit := range limits {
+			if peak[pool] > limit {
+				t.Fatalf("pool %v ran %v goroutines concurrently, limit is %v", pool, peak[pool], limit)
+			}
+		}
+		if peak["http"] <= peak["db"] {
+			t.Fatalf("http pool peak (%v) limited by db pool (%v)", peak["http"], peak["db"])
+		}
+	})
+
+	t.Run("WithSharedRateLimiter", func(t *testing.T) {
+		limiter := tickerLimiter{time.NewTicker(5 * time.Millisecond)}
+		defer limiter.Stop()
+
+		var launches atomic.Int32
+		start := time.Now()
+		Block(func(n Nursery) error {
+			for i := 0; i < 3; i++ {
+				n.Go(func() error {
+					launches.Add(1)
+					return nil
+				})
+			}
+			_, err := Map([]int{1, 2, 3}, func(ctx context.Context, v int) (int, error) {
+				launches.Add(1)
+				return v, nil
+			}, WithContext(n))
+			return err
+		}, WithSharedRateLimiter(limiter))
+
+		if launches.Load() != 6 {
+			t.Fatalf("%v goroutine(s) launched instead of 6", launches.Load())
+		}
+		// 6 launches at 1 launch per 5ms.
+		if time.Since(start) < 25*time.Millisecond {
+			t.Fatalf("launches didn't collectively respect shared rate: took %v", time.Since(start))
+		}
+	})
+
+	t.Run("StartDraining", func(t *testing.T) {
+		var inFlightErr error
+		var rejected any
+		inFlightDone := false
+
+		err := Block(func(n Nursery) error {
+			started := make(chan struct{})
+			n.Go(func() error {
+				close(started)
+				time.Sleep(10 * time.Millisecond)
+				inFlightErr = n.Err()
+				inFlightDone = true
+				return nil
+			})
+			<-started
+
+			StartDraining(n)
+			func() {
+				defer func() { rejected = recover() }()
+				n.Go(func() error { return nil })
+			}()
+			if TryGo(n, func() error { return nil }) {
+				t.Fatal("TryGo succeeded while draining")
+			}
+			if err := GoEstimated(n, 0, func() error { return nil }); err != ErrNurseryDraining {
+				t.Fatalf("GoEstimated returned %v instead of ErrNurseryDraining", err)
+			}
+			return nil
+		})
+
+		if err != nil {
+			t.Fatalf("block returned %v instead of nil", err)
+		}
+		if rejected != ErrNurseryDraining {
+			t.Fatalf("Go panicked with %v instead of ErrNurseryDraining", rejected)
+		}
+		if !inFlightDone || inFlightErr != nil {
+			t.Fatalf("in-flight goroutine interrupted: context error %v", inFlightErr)
+		}
+	})
+
+	t.Run("WithCaptureSpawnSite", func(t *testing.T) {
+		var gp GoroutinePanic
+		var line int
+		err := Block(func(n Nursery) error {
+			_, _, line, _ = runtime.Caller(0)
+			n.Go(func() error { panic("foo") })
+			return nil
+		}, WithCaptureSpawnSite(), WithPanicAsError())
+
+		if !errors.As(err, &gp) {
+			t.Fatalf("block returned %v instead of panic error", err)
+		}
+		expected := fmt.Sprintf("nursery_test.go:%v", line+1)
+		if !strings.HasSuffix(gp.SpawnSite, expected) {
+			t.Fatalf("panic spawn site is %q instead of ...%v", gp.SpawnSite, expected)
+		}
+	})
+
+	t.Run("WithMinObservedConcurrency", func(t *testing.T) {
+		t.Run("Serial", func(t *testing.T) {
+			called := false
+			var peak int
+			Block(func(n Nursery) error {
+				for i := 0; i < 3; i++ {
+					n.Go(func() error {
+						time.Sleep(time.Millisecond)
+						return nil
+					})
+				}
+				return nil
+			}, WithMaxGoroutines(1), WithMinObservedConcurrency(2, func(p int) {
+				called = true
+				peak = p
+			}))
+
+			if !called {
+				t.Fatal("min observed concurrency violation not reported")
+			}
+			if peak != 1 {
+				t.Fatalf("violation reported with peak %v instead of 1", peak)
+			}
+		})
+
+		t.Run("Parallel", func(t *testing.T) {
+			called := false
+			Block(func(n Nursery) error {
+				for i := 0; i < 4; i++ {
+					n.Go(func() error {
+						time.Sleep(5 * time.Millisecond)
+						return nil
+					})
+				}
+				return nil
+			}, WithMinObservedConcurrency(2, func(p int) {
+				called = true
+			}))
+
+			if called {
+				t.Fatal("min observed concurrency violation reported for parallel workload")
+			}
+		})
+	})
+
+	t.Run("WithNamedPoolsSpawnPanic", func(t *testing.T) {
+		Block(func(n Nursery) error {
+			release := make(chan struct{})
+			n.Go(func() error {
+				<-release
+				return nil
+			})
+
+			// Hard cap is reached, pool slot must be released.
+			func() {
+				defer func() {
+					if err, _ := recover().(error); !errors.Is(err, ErrHardCapExceeded) {
+						t.Errorf("GoPool panicked with %v instead of ErrHardCapExceeded", err)
+					}
+				}()
+				GoPool(n, "db", func() error { return nil })
+			}()
+			close(release)
+
+			// Wait for blocking goroutine to return.
+			for StatsOf(n).Active > 0 {
+				time.Sleep(time.Millisecond)
+			}
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				GoPool(n, "db", func() error { return nil })
+			}()
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("pool slot leaked after spawn panicked")
+			}
+			return nil
+		}, WithNamedPools(map[string]int{"db": 1}), WithHardGoroutineCap(1))
+	})
+
+	t.Run("CanceledContext", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// Block function used to be dropped randomly, leaving block hung,
+		// with or without goroutines limit.
+		for _, opts := range [][]BlockOption{{WithContext(ctx)}, {WithContext(ctx), WithMaxGoroutines(1)}} {
+			for i := 0; i < 100; i++ {
+				executed := false
+				err := Block(func(n Nursery) error {
+					executed = true
+					if n.Err() == nil {
+						t.Error("block nursery isn't canceled")
+					}
+					// May be dropped as nursery is canceled.
+					n.Go(func() error { return nil })
+					return nil
+				}, opts...)
+
+				if !executed {
+					t.Fatal("block function not executed with a canceled context")
+				}
+				if err != nil {
+					t.Fatalf("block returned %v instead of nil", err)
+				}
+			}
+		}
+	})
+}
+
+func timeoutDumpStuckRoutine() {
+	time.Sleep(50 * time.Millisecond)
+}
+
+func activeStacksParkedRoutine(parked chan<- struct{}, release <-chan struct{}) {
+	close(parked)
+	<-release
+}
+
+// captureHandler is a slog.Handler that stores records.
+type captureHandler struct {
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (h *captureHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *captureHandler) Handle(_ context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, r)
+	return nil
 }
+
+func (h *captureHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *captureHandler) WithGroup(string) slog.Handler { return h }
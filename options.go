@@ -2,7 +2,10 @@ package conc
 
 import (
 	"context"
+	"io"
+	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -83,5 +86,423 @@ func WithMaxGoroutines(max int) BlockOption {
 
 		// +1 because block function is a routine also.
 		n.limiter = make(chan struct{}, max+1)
+		n.maxGoroutines = max
+	}
+}
+
+// WithDefaultTaskTimeout returns a nursery block option that sets a timeout to
+// every goroutine spawned using GoCtx. GoWithTimeout overrides it.
+func WithDefaultTaskTimeout(timeout time.Duration) BlockOption {
+	return func(n *nursery) {
+		n.taskTimeout = timeout
+	}
+}
+
+// WithUnderutilizationWarning returns a nursery block option that calls the
+// given function at the end of block if peak number of goroutine running
+// concurrently is below half of the limit set with WithMaxGoroutines. This is
+// a tuning aid to right-size concurrency limits.
+func WithUnderutilizationWarning(warn func(peak, limit int)) BlockOption {
+	return func(n *nursery) {
+		n.onUnderused = warn
+	}
+}
+
+// WithMinObservedConcurrency returns a nursery block option that calls
+// onViolation at the end of block if peak number of goroutine running
+// concurrently is below min. This catches accidental serialization in
+// performance regression tests.
+func WithMinObservedConcurrency(min int, onViolation func(peak int)) BlockOption {
+	return func(n *nursery) {
+		n.minObserved = min
+		n.onMinObserved = onViolation
+	}
+}
+
+// WithNameGenerator returns a nursery block option that names goroutines
+// spawned without a name (i.e. not using GoNamed or its variants) with the
+// given generator. Generator receives index of goroutine in the nursery.
+func WithNameGenerator(gen func(index int) string) BlockOption {
+	return func(n *nursery) {
+		n.nameGen = gen
+	}
+}
+
+// WithoutPanicRecovery returns a nursery block option that disables panic
+// recovery in goroutines. A panicking goroutine crashes the process as a native
+// goroutine panic and no GoroutinePanic is produced. This removes recover
+// overhead on hot paths where panics are programmer errors.
+func WithoutPanicRecovery() BlockOption {
+	return func(n *nursery) {
+		n.noRecover = true
+	}
+}
+
+// WithOnDrainStart returns a nursery block option that calls the given function
+// once block function returns and nursery starts waiting for goroutines.
+// Function receives number of goroutines still active at that moment.
+func WithOnDrainStart(onDrainStart func(active int)) BlockOption {
+	return func(n *nursery) {
+		n.onDrainStart = onDrainStart
+	}
+}
+
+// WithCPUTimeBudget returns a nursery block option that calls onExceed once if
+// CPU time consumed during the block exceeds the given budget. Go doesn't
+// expose per-goroutine CPU time so process CPU time is sampled instead, it
+// includes goroutines outside of the nursery. On platforms where CPU time isn't
+// available, wall-clock time is used.
+func WithCPUTimeBudget(budget time.Duration, onExceed func()) BlockOption {
+	return func(n *nursery) {
+		n.cpuBudget = budget
+		n.onCPUBudgetExceeded = onExceed
+	}
+}
+
+// WithValue returns a nursery block option that stores a block-scoped value
+// shared by all goroutines. Value is retrieved using Nursery.Value and takes
+// precedence over context values with the same key. Value is shared by
+// reference, it must be safe for concurrent use.
+func WithValue(key, value any) BlockOption {
+	return func(n *nursery) {
+		if n.values == nil {
+			n.values = make(map[any]any)
+		}
+		n.values[key] = value
+	}
+}
+
+// WithCancelOnError returns a nursery block option that cancels nursery context
+// on fatal goroutine errors even if a custom error handler is provided. This is
+// the default behavior without custom error handler.
+func WithCancelOnError() BlockOption {
+	return func(n *nursery) {
+		n.cancelOnError = true
+	}
+}
+
+// WithErrorClassifier returns a nursery block option that classifies goroutine
+// errors. Fatal errors are passed to error handler and cancel nursery if it
+// cancels on error. Retryable errors are passed to error handler without
+// canceling nursery. Ignore errors are dropped.
+func WithErrorClassifier(classifier func(error) ErrorClass) BlockOption {
+	return func(n *nursery) {
+		n.classifier = classifier
+	}
+}
+
+// WithTimeoutGoroutineDump returns a nursery block option that writes current
+// stack of every running goroutine of the nursery to w if nursery deadline is
+// exceeded. This helps diagnosing hung goroutines. Block returns once dump
+// is written.
+func WithTimeoutGoroutineDump(w io.Writer) BlockOption {
+	return func(n *nursery) {
+		n.trackStacks = true
+		n.dumpWriter = w
+	}
+}
+
+// WithHardGoroutineCap returns a nursery block option that limits the maximum
+// number of goroutines spawned and not yet returned. Unlike WithMaxGoroutines,
+// spawning a goroutine above the cap doesn't block: Go and its variants panic
+// with an error wrapping ErrHardCapExceeded and TryGo fails. This is useful to
+// catch runaway fan-out bugs in tests.
+func WithHardGoroutineCap(max int) BlockOption {
+	return func(n *nursery) {
+		n.hardCap = max
+	}
+}
+
+// WithStackTracking returns a nursery block option that tracks goroutines so
+// their current stack can be retrieved using ActiveStacks.
+func WithStackTracking() BlockOption {
+	return func(n *nursery) {
+		n.trackStacks = true
+	}
+}
+
+// WithRunningTracking returns a nursery block option that tracks indices of
+// running goroutines so they can be inspected using IsRunning.
+func WithRunningTracking() BlockOption {
+	return func(n *nursery) {
+		n.running = make(map[int]struct{})
+	}
+}
+
+// WithCategoryTimeouts returns a nursery block option that sets a timeout to
+// goroutines spawned using GoNamedCtx whose name is a key of the given
+// map. Other goroutines use default task timeout, if any.
+func WithCategoryTimeouts(timeouts map[string]time.Duration) BlockOption {
+	return func(n *nursery) {
+		n.categoryTimeouts = timeouts
+	}
+}
+
+// WithPanicAsError returns a nursery block option that converts recovered
+// goroutine panics to errors instead of forwarding them. Errors are handled as
+// any other goroutine error. By default, error message is "goroutine
+// panicked: <value>" and error wraps GoroutinePanic.
+func WithPanicAsError() BlockOption {
+	return func(n *nursery) {
+		n.panicAsError = true
+	}
+}
+
+// WithPanicHandler returns a nursery block option that sets function called
+// with every recovered goroutine panic before it is forwarded. Handler is
+// executed in the panicking goroutine.
+func WithPanicHandler(handler func(GoroutinePanic)) BlockOption {
+	return func(n *nursery) {
+		n.onPanic = handler
+	}
+}
+
+// WithFirstPanicAsError returns a nursery block option that recovers goroutine
+// panics without canceling nursery so other goroutines keep running. First
+// panic is returned by Block as an error wrapping GoroutinePanic (see
+// WithPanicErrorFormatter), all panics are passed to panic handler, if any.
+func WithFirstPanicAsError() BlockOption {
+	return func(n *nursery) {
+		n.firstPanicAsError = true
+	}
+}
+
+// WithPanicErrorFormatter returns a nursery block option that sets function
+// used to convert recovered panics to errors when WithPanicAsError option is
+// used.
+func WithPanicErrorFormatter(format func(GoroutinePanic) error) BlockOption {
+	return func(n *nursery) {
+		n.formatPanic = format
+	}
+}
+
+// WithDurationHistogram returns a nursery block option that tallies duration
+// of each completed goroutine into buckets and reports counts at the end of
+// block. Goroutines that panicked aren't accounted. Buckets are ascending
+// upper bounds (inclusive), reported counts contains an extra last bucket for
+// durations above last bound.
+func WithDurationHistogram(buckets []time.Duration, report func(counts []int)) BlockOption {
+	return func(n *nursery) {
+		n.histogram = &durationHistogram{
+			buckets:  buckets,
+			counts:   make([]atomic.Int32, len(buckets)+1),
+			reportFn: report,
+		}
+	}
+}
+
+// WithMaxDepth returns a nursery block option that limits depth of nested
+// blocks. Blocks whose context derives from a nursery are nested, depth of a
+// top-level block is 1. A block nested deeper than max returns an error
+// wrapping ErrMaxDepthExceeded without executing block function. Limit is
+// inherited by nested blocks unless they override it. This catches unbounded
+// recursion bugs.
+func WithMaxDepth(max int) BlockOption {
+	return func(n *nursery) {
+		n.maxDepth = max
+	}
+}
+
+// WithAbandonAfter returns a nursery block option that abandons goroutines
+// still running after the given duration once nursery is canceled. Block stops
+// waiting for them, calls onAbandon with their indices and then returns.
+// Abandoned goroutines keep running detached: they are leaked and their error
+// and panics are ignored. This lets a service shut down despite a misbehaving
+// goroutine. Block end proceeds as usual without waiting for abandoned
+// goroutines: registered closers are closed and end of block reports are
+// emitted, but they don't account abandoned goroutines still running.
+// Channel returned by Ended is closed once abandoned goroutines have returned.
+func WithAbandonAfter(d time.Duration, onAbandon func(indices []int)) BlockOption {
+	return func(n *nursery) {
+		n.abandonAfter = d
+		n.onAbandon = onAbandon
+		n.abandoned = make(chan struct{})
+		// Abandoned goroutines are reported by index.
+		n.running = make(map[int]struct{})
+	}
+}
+
+// WithHooks returns a nursery block option that sets functions called on
+// goroutines lifecycle events. Hooks are executed in the goroutine they
+// describe.
+func WithHooks(hooks Hooks) BlockOption {
+	return func(n *nursery) {
+		n.hooks = hooks
+	}
+}
+
+// WithIDAllocator returns a nursery block option that sets function used to
+// allocate goroutines ID reported in hooks and panics. By default, ID is the
+// index of the goroutine in the nursery. A custom allocator (e.g. a snowflake
+// generator) ensures global uniqueness across nurseries. Allocator may be
+// called concurrently.
+func WithIDAllocator(alloc func() uint64) BlockOption {
+	return func(n *nursery) {
+		n.idAlloc = alloc
+	}
+}
+
+// WithName returns a nursery block option that sets nursery name. Name is used
+// as metrics prefix in WriteMetrics.
+func WithName(name string) BlockOption {
+	return func(n *nursery) {
+		n.name = name
+	}
+}
+
+// WithEmitRateLimit returns a nursery block option that paces values emitted by
+// producers of streaming helpers (BlockStream, StreamWithDone, Generate,
+// ProcessResults and ProducerConsumer) using the given rate limiter. This
+// provides rate-based backpressure to protect a slow downstream. Emit returns
+// without sending value if nursery is canceled while waiting.
+func WithEmitRateLimit(limiter RateLimiter) BlockOption {
+	return func(n *nursery) {
+		n.emitLimiter = limiter
+	}
+}
+
+// WithMaxQueuedDuration returns a nursery block option that bounds the sum of
+// estimated durations of goroutines spawned using GoEstimated and not
+// yet started. This lets callers shed load when expected latency would be
+// unacceptable.
+func WithMaxQueuedDuration(d time.Duration) BlockOption {
+	return func(n *nursery) {
+		n.maxQueuedDuration = d
+	}
+}
+
+// WithLogger returns a nursery block option that sets logger used by nursery.
+func WithLogger(logger *slog.Logger) BlockOption {
+	return func(n *nursery) {
+		n.logger = logger
+	}
+}
+
+// WithAutoErrorLogging returns a nursery block option that logs every
+// goroutine error at error level using logger set with WithLogger option.
+// Records contain goroutine index, name and duration fields. Errors are still
+// handled as usual.
+func WithAutoErrorLogging() BlockOption {
+	return func(n *nursery) {
+		n.autoErrorLogging = true
+	}
+}
+
+// WithSummaryError returns a nursery block option that collects goroutine
+// errors and panics without canceling nursery. If any goroutine failed, Block
+// returns a *SummaryError counting failures and wrapping collected errors.
+func WithSummaryError() BlockOption {
+	return func(n *nursery) {
+		n.summary = &summary{}
+		n.onError = func(err error) {
+			n.summary.add(err, false)
+		}
+	}
+}
+
+// WithHealthGate returns a nursery block option that blocks spawning of
+// goroutines while check returns false. Check is polled every poll duration
+// until it returns true or nursery is canceled. TryGo fails instead of
+// blocking.
+func WithHealthGate(check func() bool, poll time.Duration) BlockOption {
+	return func(n *nursery) {
+		n.healthCheck = check
+		n.healthPoll = poll
+	}
+}
+
+// WithTopSlowest returns a nursery block option that calls report at the end
+// of block with outcomes of the count goroutines that ran the longest, slowest
+// first. Goroutines that panicked aren't accounted.
+func WithTopSlowest(count int, report func([]GoroutineOutcome)) BlockOption {
+	return func(n *nursery) {
+		n.slowest = &slowest{max: count, reportFn: report}
+	}
+}
+
+// WithTaskMiddleware returns a nursery block option that wraps routine of
+// every spawned goroutine with middleware. Unlike hooks, middlewares can alter
+// routines behavior (e.g. inject latency or failures for testing). Multiple
+// middlewares compose in order: first one is outermost.
+func WithTaskMiddleware(middleware func(next Routine) Routine) BlockOption {
+	return func(n *nursery) {
+		n.middlewares = append(n.middlewares, middleware)
+	}
+}
+
+// WithNamedPools returns a nursery block option that defines named pools with
+// their own concurrency limit. Goroutines spawned using GoPool are
+// gated by their pool limit, independently of other pools. This function
+// panics if a limit isn't a positive integer.
+func WithNamedPools(pools map[string]int) BlockOption {
+	return func(n *nursery) {
+		n.pools = make(map[string]limiter, len(pools))
+		for name, max := range pools {
+			if max <= 0 {
+				panic("pool limit must be a positive integer")
+			}
+			n.pools[name] = make(limiter, max)
+		}
+	}
+}
+
+// WithSharedRateLimiter returns a nursery block option that sets a rate
+// limiter shared by all goroutines launches of the block and of blocks nested
+// in it (e.g. helpers such as Map using WithContext option with a nursery of
+// the block). Spawning a goroutine waits for the limiter, except for
+// TryGo which never blocks.
+func WithSharedRateLimiter(limiter RateLimiter) BlockOption {
+	return WithValue(sharedLimiterKey{}, limiter)
+}
+
+// WithInFlightByteLimit returns a nursery block option that limits the sum of
+// estimated bytes of goroutines spawned using GoBytes and not yet
+// returned. This bounds memory used by concurrent transfers.
+func WithInFlightByteLimit(max int64) BlockOption {
+	return func(n *nursery) {
+		n.bytes = newWeightedSemaphore(max)
+	}
+}
+
+// WithTracer returns a nursery block option that sets tracer used by
+// GoTraced.
+func WithTracer(tracer Tracer) BlockOption {
+	return func(n *nursery) {
+		n.tracer = tracer
+	}
+}
+
+// WithEventLog returns a nursery block option that records nursery events
+// (goroutines spawn, start, error, panic and finish, cancellation and end of
+// block) into log.
+func WithEventLog(log *EventLog) BlockOption {
+	return func(n *nursery) {
+		n.eventLog = log
+	}
+}
+
+// WithCaptureSpawnSite returns a nursery block option that captures file:line
+// of calls spawning goroutines. Spawn site is reported in GoroutinePanic and
+// hooks, it helps finding where a misbehaving goroutine was launched. Calls
+// from helpers of this package are attributed to their caller.
+func WithCaptureSpawnSite() BlockOption {
+	return func(n *nursery) {
+		n.captureSpawnSite = true
+	}
+}
+
+// WithErrorRateThrottle returns a nursery block option that adjusts the
+// maximum number of goroutines executing concurrently to keep observed error
+// rate near target. Limit, initially max, decreases when error rate is above
+// target and increases otherwise, within [min, max]. Spawning a goroutine
+// blocks while limit is reached. Current limit is reported by StatsOf.
+// This protects failing downstreams by backing off automatically. This
+// function panics if min isn't positive or is above max.
+func WithErrorRateThrottle(target float64, min, max int) BlockOption {
+	if min <= 0 || min > max {
+		panic("error rate throttle bounds must satisfy 0 < min <= max")
+	}
+	return func(n *nursery) {
+		n.throttle = newErrorRateThrottle(target, min, max)
 	}
 }
@@ -8,10 +8,22 @@ import (
 type GoroutinePanic struct {
 	Value any
 	Stack string
+	// Name of the goroutine, it is empty for unnamed goroutines.
+	Name string
+	// Index of the goroutine in the nursery, see GoIndexed.
+	Index int
+	// ID of the goroutine, see WithIDAllocator.
+	ID uint64
+	// SpawnSite is file:line of the call that spawned the goroutine, see
+	// WithCaptureSpawnSite.
+	SpawnSite string
 }
 
 // String implements fmt.Stringer.
 func (gp GoroutinePanic) String() string {
+	if gp.Name != "" {
+		return fmt.Sprintf("%v: %v\n%v", gp.Name, gp.Value, gp.Stack)
+	}
 	return fmt.Sprintf("%v\n%v", gp.Value, gp.Stack)
 }
 
@@ -28,3 +40,18 @@ func (gp GoroutinePanic) Unwrap() error {
 
 	return nil
 }
+
+// panicError is the default error of a recovered panic converted to an error.
+type panicError struct {
+	GoroutinePanic
+}
+
+// Error implements error.
+func (pe panicError) Error() string {
+	return fmt.Sprintf("goroutine panicked: %v", pe.Value)
+}
+
+// Unwrap returns underlying GoroutinePanic.
+func (pe panicError) Unwrap() error {
+	return pe.GoroutinePanic
+}
@@ -0,0 +1,125 @@
+package conc
+
+import (
+	"container/heap"
+)
+
+// pendingTask define a task waiting for a goroutine slot.
+type pendingTask struct {
+	task
+	priority int
+	seq      int
+}
+
+// pendingQueue is a priority queue of pending tasks. It implements
+// heap.Interface.
+type pendingQueue struct {
+	tasks []pendingTask
+	seq   int
+}
+
+func (pq *pendingQueue) Len() int { return len(pq.tasks) }
+
+func (pq *pendingQueue) Less(i, j int) bool {
+	if pq.tasks[i].priority == pq.tasks[j].priority {
+		return pq.tasks[i].seq < pq.tasks[j].seq
+	}
+	return pq.tasks[i].priority > pq.tasks[j].priority
+}
+
+func (pq *pendingQueue) Swap(i, j int) {
+	pq.tasks[i], pq.tasks[j] = pq.tasks[j], pq.tasks[i]
+}
+
+func (pq *pendingQueue) Push(x any) {
+	pq.tasks = append(pq.tasks, x.(pendingTask))
+}
+
+func (pq *pendingQueue) Pop() any {
+	last := len(pq.tasks) - 1
+	t := pq.tasks[last]
+	pq.tasks[last] = pendingTask{}
+	pq.tasks = pq.tasks[:last]
+	return t
+}
+
+// GoPriority is the same as n.Go except when goroutines limit is reached,
+// routine is queued without blocking and pending routines are started by
+// descending priority. Routines of equal priority are started in submission
+// order.
+func GoPriority(n Nursery, priority int, routine Routine) {
+	impl, ok := n.(*nursery)
+	if !ok {
+		n.Go(routine)
+		return
+	}
+	impl.goPriority(priority, routine)
+}
+
+func (n *nursery) goPriority(priority int, routine Routine) {
+	t := n.newTask(routine, "")
+	if n.limiter == nil {
+		// Routines are started immediately.
+		n.goTask(t)
+		return
+	}
+
+	n.pendingMu.Lock()
+	heap.Push(&n.pending, pendingTask{task: t, priority: priority, seq: n.pending.seq})
+	n.pending.seq++
+	startDispatch := !n.dispatching
+	n.dispatching = true
+	n.pendingMu.Unlock()
+
+	if startDispatch {
+		// Dispatcher is accounted as a routine so block waits for pending
+		// tasks.
+		n.routinesCount.Add(1)
+		go n.dispatch()
+	}
+}
+
+// dispatch starts pending tasks by priority until queue is empty. Pending
+// tasks are popped once a goroutine is available so tasks queued or
+// reprioritized while waiting are accounted.
+func (n *nursery) dispatch() {
+	placeholder := task{
+		resolve: func() task {
+			n.pendingMu.Lock()
+			defer n.pendingMu.Unlock()
+			n.claimed--
+			return heap.Pop(&n.pending).(pendingTask).task
+		},
+	}
+
+	for {
+		n.pendingMu.Lock()
+		if n.pending.Len() <= n.claimed {
+			n.dispatching = false
+			n.pendingMu.Unlock()
+			break
+		}
+		n.claimed++
+		n.pendingMu.Unlock()
+
+		// Blocks until a goroutine is available.
+		n.goTask(placeholder)
+	}
+
+	n.report(nil)
+}
+
+// Reprioritize changes priority of pending routines of nursery n whose index
+// matches the given predicate. Running routines are unaffected.
+func Reprioritize(n Nursery, match func(index int) bool, priority int) {
+	impl := mustNursery(n, "Reprioritize")
+	impl.pendingMu.Lock()
+	defer impl.pendingMu.Unlock()
+
+	for i := range impl.pending.tasks {
+		if match(impl.pending.tasks[i].index) {
+			impl.pending.tasks[i].priority = priority
+		}
+	}
+	heap.Init(&impl.pending)
+}
@@ -0,0 +1,72 @@
+package conc
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestPriority(t *testing.T) {
+	// run occupies the single goroutine slot and queues routines with given
+	// priorities. It returns routines indices in execution order.
+	run := func(priorities []int, reprioritize func(n Nursery)) []int {
+		var mu sync.Mutex
+		var order []int
+		var wg sync.WaitGroup
+
+		Block(func(n Nursery) error {
+			release := make(chan struct{})
+			started := make(chan struct{})
+			n.Go(func() error {
+				close(started)
+				<-release
+				return nil
+			})
+			<-started
+
+			for i, p := range priorities {
+				index := i + 1
+				wg.Add(1)
+				GoPriority(n, p, func() error {
+					defer wg.Done()
+					mu.Lock()
+					order = append(order, index)
+					mu.Unlock()
+					return nil
+				})
+			}
+			if reprioritize != nil {
+				reprioritize(n)
+			}
+
+			close(release)
+			// Keep block goroutine busy so a single routine runs at a time.
+			wg.Wait()
+			return nil
+		}, WithMaxGoroutines(1))
+
+		return order
+	}
+
+	t.Run("HighestFirst", func(t *testing.T) {
+		order := run([]int{0, 0, 0, 10}, nil)
+		if len(order) != 4 {
+			t.Fatalf("%v routines executed instead of 4", len(order))
+		}
+		if !slices.Equal(order, []int{4, 1, 2, 3}) {
+			t.Fatalf("routines ran in order %v instead of [4 1 2 3]", order)
+		}
+	})
+
+	t.Run("Reprioritize", func(t *testing.T) {
+		order := run([]int{0, 0, 0, 0}, func(n Nursery) {
+			Reprioritize(n, func(index int) bool { return index == 4 }, 10)
+		})
+		if len(order) != 4 {
+			t.Fatalf("%v routines executed instead of 4", len(order))
+		}
+		if !slices.Equal(order, []int{4, 1, 2, 3}) {
+			t.Fatalf("routines ran in order %v instead of [4 1 2 3]", order)
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package conc
+
+import (
+	"context"
+	"sync"
+)
+
+// ProducerConsumer coordinates producers and consumers sharing a buffered
+// channel. On cancel, producers are signaled and waited for first, then
+// consumers drain remaining buffered values before block ends so no emitted
+// value is lost.
+type ProducerConsumer[T any] struct {
+	mu        sync.Mutex
+	buffer    int
+	producers []func(ctx context.Context, emit func(T)) error
+	consumers []func(ctx context.Context, v T) error
+}
+
+// NewProducerConsumer returns a new ProducerConsumer whose channel buffers up
+// to the given number of values.
+func NewProducerConsumer[T any](buffer int) *ProducerConsumer[T] {
+	return &ProducerConsumer[T]{buffer: buffer}
+}
+
+// Producer registers a producer. Producer context is canceled on shutdown,
+// emit returns without sending value once it is done. Emit rate can be limited
+// using WithEmitRateLimit option.
+func (pc *ProducerConsumer[T]) Producer(producer func(ctx context.Context, emit func(T)) error) {
+	pc.mu.Lock()
+	pc.producers = append(pc.producers, producer)
+	pc.mu.Unlock()
+}
+
+// Consumer registers a consumer. Consumer is called for every value it
+// receives. Its context isn't canceled on shutdown so buffered values can be
+// processed, it is only canceled if a goroutine returns an error.
+func (pc *ProducerConsumer[T]) Consumer(consumer func(ctx context.Context, v T) error) {
+	pc.mu.Lock()
+	pc.consumers = append(pc.consumers, consumer)
+	pc.mu.Unlock()
+}
+
+// Run spawns registered producers and consumers in a nursery with the given
+// options and waits for them to return. Shutdown starts once ctx is canceled,
+// an error occurs or all producers have returned. Run returns nil on
+// shutdown caused by ctx.
+func (pc *ProducerConsumer[T]) Run(ctx context.Context, opts ...BlockOption) error {
+	pc.mu.Lock()
+	producers := pc.producers
+	consumers := pc.consumers
+	pc.mu.Unlock()
+
+	// Nursery isn't canceled with ctx as it would drop consumers.
+	return Block(func(n Nursery) error {
+		ch := make(chan T, pc.buffer)
+
+		// Producers context is canceled on shutdown.
+		pctx, cancel := context.WithCancel(n)
+		stop := context.AfterFunc(ctx, cancel)
+
+		for _, c := range consumers {
+			consumer := c
+			n.Go(func() error {
+				var err error
+				for v := range ch {
+					// Keep draining after an error so producers aren't blocked.
+					if err == nil {
+						err = consumer(n, v)
+						if err != nil {
+							cancel()
+						}
+					}
+				}
+				return err
+			})
+		}
+
+		n.Go(func() error {
+			defer close(ch)
+			defer cancel()
+			defer stop()
+
+			return Block(func(pn Nursery) error {
+				limiter := emitLimiter(n)
+				emit := func(v T) {
+					if limiter != nil && limiter.Wait(pn) != nil {
+						return
+					}
+					select {
+					case ch <- v:
+					case <-pn.Done():
+					}
+				}
+
+				for _, p := range producers {
+					producer := p
+					pn.Go(func() error {
+						return producer(pn, emit)
+					})
+				}
+
+				return nil
+			}, WithContext(pctx))
+		})
+
+		return nil
+	}, append([]BlockOption{WithContext(context.WithoutCancel(ctx))}, opts...)...)
+}
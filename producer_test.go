@@ -0,0 +1,94 @@
+package conc
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestProducerConsumer(t *testing.T) {
+	t.Run("DrainOnCancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var consumed atomic.Int32
+		pc := NewProducerConsumer[int](16)
+		pc.Producer(func(ctx context.Context, emit func(int)) error {
+			// Fill buffer then cancel.
+			for i := 0; i < 16; i++ {
+				emit(i)
+			}
+			cancel()
+			<-ctx.Done()
+			return nil
+		})
+		pc.Consumer(func(ctx context.Context, v int) error {
+			if ctx.Err() != nil {
+				t.Error("consumer context canceled")
+			}
+			// Slow consumer so values are still buffered on cancel.
+			time.Sleep(time.Millisecond)
+			consumed.Add(1)
+			return nil
+		})
+
+		err := pc.Run(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if consumed.Load() != 16 {
+			t.Fatalf("%v value(s) consumed instead of 16", consumed.Load())
+		}
+	})
+
+	t.Run("ProducersReturn", func(t *testing.T) {
+		var sum atomic.Int32
+		pc := NewProducerConsumer[int](0)
+		for p := 0; p < 3; p++ {
+			pc.Producer(func(ctx context.Context, emit func(int)) error {
+				for i := 1; i <= 10; i++ {
+					emit(i)
+				}
+				return nil
+			})
+		}
+		for c := 0; c < 2; c++ {
+			pc.Consumer(func(ctx context.Context, v int) error {
+				sum.Add(int32(v))
+				return nil
+			})
+		}
+
+		err := pc.Run(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if sum.Load() != 165 {
+			t.Fatalf("consumed sum is %v instead of 165", sum.Load())
+		}
+	})
+
+	t.Run("ConsumerError", func(t *testing.T) {
+		pc := NewProducerConsumer[int](4)
+		pc.Producer(func(ctx context.Context, emit func(int)) error {
+			// Infinite producer.
+			for i := 0; ctx.Err() == nil; i++ {
+				emit(i)
+			}
+			return nil
+		})
+		pc.Consumer(func(ctx context.Context, v int) error {
+			if v == 3 {
+				return io.EOF
+			}
+			return nil
+		})
+
+		err := pc.Run(context.Background())
+		if err != io.EOF {
+			t.Fatalf("Run returned %v instead of io.EOF", err)
+		}
+	})
+}
@@ -0,0 +1,12 @@
+package conc
+
+import "context"
+
+// RateLimiter define a rate limiter. Wait blocks until an event is allowed or
+// context is done. *rate.Limiter of golang.org/x/time/rate package implements
+// this interface.
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+type sharedLimiterKey struct{}
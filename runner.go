@@ -0,0 +1,79 @@
+package conc
+
+import "context"
+
+// Runner is a long-lived nursery tied to a component lifecycle instead of a
+// function closure. Goroutines are spawned between Start and Stop using Go.
+// Zero value is a runner ready to start. A runner must not be restarted.
+type Runner struct {
+	n     Nursery
+	done  chan struct{}
+	err   error
+	panic any
+}
+
+// Start starts runner nursery with the given options. It must be called once
+// before any other method. If block ends before nursery is started (e.g.
+// because of WithMaxDepth), Start returns and Stop returns block error. Panics
+// of block options are forwarded to Start caller.
+func (r *Runner) Start(opts ...BlockOption) {
+	started := make(chan struct{})
+	r.done = make(chan struct{})
+
+	go func() {
+		defer close(r.done)
+		defer func() {
+			// Forward panic to Stop caller.
+			r.panic = recover()
+		}()
+
+		r.err = Block(func(n Nursery) error {
+			r.n = n
+			close(started)
+			<-n.Done()
+			return nil
+		}, opts...)
+	}()
+
+	select {
+	case <-started:
+	case <-r.done:
+		// Block ended without executing its function.
+		if r.panic != nil {
+			panic(r.panic)
+		}
+	}
+}
+
+// Go executes routine in a goroutine of runner nursery. It panics with
+// ErrNurseryDone if nursery wasn't started.
+func (r *Runner) Go(routine Routine) {
+	if r.n == nil {
+		panic(ErrNurseryDone)
+	}
+	r.n.Go(routine)
+}
+
+// Nursery returns runner nursery or nil if it wasn't started.
+func (r *Runner) Nursery() Nursery {
+	return r.n
+}
+
+// Stop cancels runner nursery and waits for its goroutines to return. It
+// returns nursery error or context error if context is done before goroutines
+// have returned. Recovered goroutine panics are forwarded to Stop caller.
+func (r *Runner) Stop(ctx context.Context) error {
+	if r.n != nil {
+		r.n.(*nursery).cancel()
+	}
+
+	select {
+	case <-r.done:
+		if r.panic != nil {
+			panic(r.panic)
+		}
+		return r.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
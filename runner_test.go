@@ -0,0 +1,90 @@
+package conc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunner(t *testing.T) {
+	t.Run("StopDrains", func(t *testing.T) {
+		var r Runner
+		r.Start()
+
+		var done atomic.Int32
+		for i := 0; i < 3; i++ {
+			r.Go(func() error {
+				<-r.Nursery().Done()
+				time.Sleep(time.Millisecond)
+				done.Add(1)
+				return nil
+			})
+		}
+
+		if err := r.Stop(context.Background()); err != nil {
+			t.Fatalf("Stop returned %v instead of nil", err)
+		}
+		if done.Load() != 3 {
+			t.Fatalf("Stop returned before %v goroutine(s) completed", 3-done.Load())
+		}
+	})
+
+	t.Run("StopDeadline", func(t *testing.T) {
+		var r Runner
+		r.Start()
+
+		release := make(chan struct{})
+		defer close(release)
+		r.Go(func() error {
+			<-release
+			return nil
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if err := r.Stop(ctx); err != context.DeadlineExceeded {
+			t.Fatalf("Stop returned %v instead of context.DeadlineExceeded", err)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		var r Runner
+		r.Start()
+		r.Go(func() error {
+			return io.EOF
+		})
+
+		if err := r.Stop(context.Background()); err != io.EOF {
+			t.Fatalf("Stop returned %v instead of io.EOF", err)
+		}
+	})
+
+	t.Run("NotStarted", func(t *testing.T) {
+		Block(func(n Nursery) error {
+			var r Runner
+			// Runner nursery is nested too deep to start.
+			r.Start(WithContext(n))
+
+			if err := r.Stop(context.Background()); !errors.Is(err, ErrMaxDepthExceeded) {
+				t.Fatalf("Stop returned %v instead of ErrMaxDepthExceeded", err)
+			}
+			return nil
+		}, WithMaxDepth(1))
+	})
+
+	t.Run("OptionPanic", func(t *testing.T) {
+		defer func() {
+			if p := recover(); p != "bad option" {
+				t.Fatalf("Start panicked with %v instead of option panic", p)
+			}
+		}()
+
+		var r Runner
+		r.Start(func(n *nursery) {
+			panic("bad option")
+		})
+	})
+}
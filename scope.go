@@ -0,0 +1,52 @@
+package conc
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+)
+
+// Scope is a nursery whose lifetime is tied to an object embedding it. Unlike
+// Runner, a Scope dropped without being closed is detected when it is garbage
+// collected.
+type Scope struct {
+	runner *Runner
+	closed atomic.Bool
+}
+
+// NewScope starts and returns a new scope. If onLeak is non-nil, it is called
+// if scope is garbage collected without Close being called. Scope nursery is
+// then canceled. onLeak is executed in finalizers goroutine.
+func NewScope(onLeak func(), opts ...BlockOption) *Scope {
+	r := &Runner{}
+	r.Start(opts...)
+
+	s := &Scope{runner: r}
+	if onLeak != nil {
+		runtime.SetFinalizer(s, func(s *Scope) {
+			if !s.closed.Load() {
+				onLeak()
+				s.runner.n.(*nursery).cancel()
+			}
+		})
+	}
+
+	return s
+}
+
+// Go executes routine in a goroutine of scope nursery.
+func (s *Scope) Go(routine Routine) {
+	s.runner.Go(routine)
+}
+
+// Nursery returns scope nursery.
+func (s *Scope) Nursery() Nursery {
+	return s.runner.Nursery()
+}
+
+// Close cancels scope nursery, waits for its goroutines to return and returns
+// nursery error.
+func (s *Scope) Close() error {
+	s.closed.Store(true)
+	return s.runner.Stop(context.Background())
+}
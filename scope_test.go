@@ -0,0 +1,44 @@
+package conc
+
+import (
+	"io"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestScope(t *testing.T) {
+	t.Run("Close", func(t *testing.T) {
+		s := NewScope(func() {
+			t.Error("closed scope reported as leaked")
+		})
+		s.Go(func() error {
+			<-s.Nursery().Done()
+			return io.EOF
+		})
+
+		if err := s.Close(); err != io.EOF {
+			t.Fatalf("Close returned %v instead of io.EOF", err)
+		}
+		runtime.GC()
+	})
+
+	t.Run("Leak", func(t *testing.T) {
+		leaked := make(chan struct{})
+		func() {
+			_ = NewScope(func() { close(leaked) })
+		}()
+
+		deadline := time.After(time.Second)
+		for {
+			runtime.GC()
+			select {
+			case <-leaked:
+				return
+			case <-deadline:
+				t.Fatal("dropped scope didn't trigger leak hook")
+			case <-time.After(time.Millisecond):
+			}
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package conc
+
+import (
+	"context"
+	"sync"
+)
+
+// weightedSemaphore is a semaphore whose tokens have a weight. Its maximum
+// weight can be changed at runtime.
+type weightedSemaphore struct {
+	mu       sync.Mutex
+	max      int64
+	used     int64
+	released chan struct{}
+}
+
+func newWeightedSemaphore(max int64) *weightedSemaphore {
+	return &weightedSemaphore{max: max, released: make(chan struct{})}
+}
+
+// acquire acquires n weight from semaphore. It blocks until weight is
+// available and reports whether it succeeded before context was canceled.
+func (s *weightedSemaphore) acquire(ctx context.Context, n int64) bool {
+	for {
+		s.mu.Lock()
+		if s.used+n <= s.max {
+			s.used += n
+			s.mu.Unlock()
+			return true
+		}
+		released := s.released
+		s.mu.Unlock()
+
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// release releases n weight and wakes up waiters.
+func (s *weightedSemaphore) release(n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.used -= n
+	close(s.released)
+	s.released = make(chan struct{})
+}
+
+// tryAcquire acquires n weight from semaphore without blocking and reports
+// whether it succeeded.
+func (s *weightedSemaphore) tryAcquire(n int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.used+n <= s.max {
+		s.used += n
+		return true
+	}
+	return false
+}
+
+// setMax changes maximum weight of semaphore and wakes up waiters.
+func (s *weightedSemaphore) setMax(max int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.max = max
+	close(s.released)
+	s.released = make(chan struct{})
+}
+
+// limit returns maximum weight of semaphore.
+func (s *weightedSemaphore) limit() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.max
+}
@@ -0,0 +1,33 @@
+package conc
+
+import "context"
+
+// Serve starts a nursery block that loops calling accept and handles every
+// accepted item in its own goroutine. Use WithMaxGoroutines option to bound
+// the number of concurrent handlers. Once accept returns an error or context
+// is canceled, Serve stops accepting and waits for in-flight handlers to
+// return. It returns block error, if any, or accept error otherwise. Accept
+// error is ignored if it is caused by context cancellation.
+func Serve[T any](ctx context.Context, accept func(context.Context) (T, error), handle func(context.Context, T) error, opts ...BlockOption) error {
+	var acceptErr error
+	err := Block(func(n Nursery) error {
+		for {
+			item, err := accept(n)
+			if err != nil {
+				if n.Err() == nil {
+					acceptErr = err
+				}
+				return nil
+			}
+
+			n.Go(func() error {
+				return handle(n, item)
+			})
+		}
+	}, append([]BlockOption{WithContext(ctx)}, opts...)...)
+
+	if err != nil {
+		return err
+	}
+	return acceptErr
+}
@@ -0,0 +1,57 @@
+package conc
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestServe(t *testing.T) {
+	t.Run("DrainOnAcceptError", func(t *testing.T) {
+		errClosed := errors.New("listener closed")
+		var accepted, handled atomic.Int32
+
+		err := Serve(context.Background(), func(ctx context.Context) (int, error) {
+			if accepted.Load() == 3 {
+				return 0, errClosed
+			}
+			return int(accepted.Add(1)), nil
+		}, func(ctx context.Context, conn int) error {
+			time.Sleep(10 * time.Millisecond)
+			if ctx.Err() == nil {
+				handled.Add(1)
+			}
+			return nil
+		}, WithMaxGoroutines(2))
+
+		if err != errClosed {
+			t.Fatalf("Serve returned %v instead of accept error", err)
+		}
+		if handled.Load() != 3 {
+			t.Fatalf("%v in-flight handler(s) drained instead of 3", handled.Load())
+		}
+	})
+
+	t.Run("ContextCanceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var handled atomic.Bool
+
+		err := Serve(ctx, func(ctx context.Context) (int, error) {
+			if handled.Load() {
+				cancel()
+				<-ctx.Done()
+				return 0, ctx.Err()
+			}
+			return 1, nil
+		}, func(ctx context.Context, conn int) error {
+			handled.Store(true)
+			return nil
+		})
+
+		if err != nil {
+			t.Fatalf("Serve returned %v instead of nil", err)
+		}
+	})
+}
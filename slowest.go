@@ -0,0 +1,71 @@
+package conc
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// GoroutineOutcome describes a goroutine that returned.
+type GoroutineOutcome struct {
+	// Index of the goroutine in the nursery.
+	Index int
+	// Name of the goroutine, it is empty for unnamed goroutines.
+	Name string
+	// Duration of the goroutine routine.
+	Duration time.Duration
+	// Err is the error returned by the routine.
+	Err error
+}
+
+// outcomeHeap is a min-heap of outcomes ordered by duration.
+type outcomeHeap []GoroutineOutcome
+
+func (h outcomeHeap) Len() int           { return len(h) }
+func (h outcomeHeap) Less(i, j int) bool { return h[i].Duration < h[j].Duration }
+func (h outcomeHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *outcomeHeap) Push(x any) {
+	*h = append(*h, x.(GoroutineOutcome))
+}
+
+func (h *outcomeHeap) Pop() any {
+	old := *h
+	o := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return o
+}
+
+// slowest keeps the max slowest goroutines outcomes.
+type slowest struct {
+	mu       sync.Mutex
+	max      int
+	outcomes outcomeHeap
+	reportFn func([]GoroutineOutcome)
+}
+
+func (s *slowest) observe(o GoroutineOutcome) {
+	if s.max <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.outcomes) < s.max {
+		heap.Push(&s.outcomes, o)
+	} else if o.Duration > s.outcomes[0].Duration {
+		s.outcomes[0] = o
+		heap.Fix(&s.outcomes, 0)
+	}
+}
+
+func (s *slowest) report() {
+	s.mu.Lock()
+	outcomes := make([]GoroutineOutcome, len(s.outcomes))
+	for i := len(outcomes) - 1; i >= 0; i-- {
+		outcomes[i] = heap.Pop(&s.outcomes).(GoroutineOutcome)
+	}
+	s.mu.Unlock()
+
+	s.reportFn(outcomes)
+}
@@ -0,0 +1,76 @@
+package conc
+
+import (
+	"context"
+	"slices"
+)
+
+// sortSequentialThreshold define slice length below which SortFunc sorts
+// sequentially.
+const sortSequentialThreshold = 4096
+
+// SortFunc sorts slice s in place in ascending order as determined by cmp
+// function using a parallel merge sort. Sub-slices are sorted in separate
+// goroutines while goroutines limit isn't reached, small slices are sorted
+// sequentially using slices.SortFunc. Sort isn't stable.
+func SortFunc[T any](ctx context.Context, s []T, cmp func(a, b T) int, opts ...BlockOption) error {
+	if len(s) <= sortSequentialThreshold {
+		slices.SortFunc(s, cmp)
+		return nil
+	}
+
+	buf := make([]T, len(s))
+	return Block(func(n Nursery) error {
+		return mergeSort(n, s, buf, cmp)
+	}, append([]BlockOption{WithContext(ctx)}, opts...)...)
+}
+
+func mergeSort[T any](n Nursery, s, buf []T, cmp func(a, b T) int) error {
+	if len(s) <= sortSequentialThreshold {
+		slices.SortFunc(s, cmp)
+		return nil
+	}
+	if err := n.Err(); err != nil {
+		return err
+	}
+
+	mid := len(s) / 2
+	left, right := s[:mid], s[mid:]
+
+	// Sort left half in a separate goroutine if one is available.
+	leftDone := make(chan error, 1)
+	started := TryGo(n, func() error {
+		err := mergeSort(n, left, buf[:mid], cmp)
+		leftDone <- err
+		return err
+	})
+	if !started {
+		leftDone <- mergeSort(n, left, buf[:mid], cmp)
+	}
+
+	err := mergeSort(n, right, buf[mid:], cmp)
+	if leftErr := <-leftDone; leftErr != nil {
+		return leftErr
+	}
+	if err != nil {
+		return err
+	}
+
+	// Merge halves.
+	i, j, k := 0, 0, 0
+	for i < len(left) && j < len(right) {
+		if cmp(right[j], left[i]) < 0 {
+			buf[k] = right[j]
+			j++
+		} else {
+			buf[k] = left[i]
+			i++
+		}
+		k++
+	}
+	k += copy(buf[k:], left[i:])
+	copy(buf[k:], right[j:])
+	copy(s, buf[:len(s)])
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package conc
+
+import (
+	"cmp"
+	"context"
+	"math/rand"
+	"slices"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSortFunc(t *testing.T) {
+	t.Run("Random", func(t *testing.T) {
+		for _, size := range []int{0, 10, sortSequentialThreshold + 1, 100_000} {
+			s := make([]int, size)
+			for i := range s {
+				s[i] = rand.Intn(1000)
+			}
+			expected := slices.Clone(s)
+			slices.SortFunc(expected, cmp.Compare[int])
+
+			err := SortFunc(context.Background(), s, cmp.Compare[int])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(s, expected) {
+				t.Fatalf("slice of %v elements isn't sorted", size)
+			}
+		}
+	})
+
+	t.Run("WithMaxGoroutines", func(t *testing.T) {
+		s := make([]int, 100_000)
+		for i := range s {
+			s[i] = rand.Int()
+		}
+
+		var concurrent, peak atomic.Int32
+		err := SortFunc(context.Background(), s, func(a, b int) int {
+			c := concurrent.Add(1)
+			defer concurrent.Add(-1)
+			for {
+				p := peak.Load()
+				if c <= p || peak.CompareAndSwap(p, c) {
+					break
+				}
+			}
+			return cmp.Compare(a, b)
+		}, WithMaxGoroutines(2))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !slices.IsSorted(s) {
+			t.Fatal("slice isn't sorted")
+		}
+		// 2 goroutines and block goroutine.
+		if peak.Load() > 3 {
+			t.Fatalf("%v goroutines sorted concurrently instead of at most 3", peak.Load())
+		}
+	})
+}
@@ -0,0 +1,31 @@
+package conc
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// packageDir is the directory of this package source files.
+var packageDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// spawnSite returns file:line of the first caller outside of this package.
+func spawnSite() string {
+	pc := make([]uintptr, 32)
+	count := runtime.Callers(2, pc)
+	frames := runtime.CallersFrames(pc[:count])
+	for {
+		frame, more := frames.Next()
+		internal := filepath.Dir(frame.File) == packageDir && !strings.HasSuffix(frame.File, "_test.go")
+		if !internal {
+			return fmt.Sprintf("%v:%v", frame.File, frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package conc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+)
+
+// goroutineID returns ID of the current goroutine as displayed in stack traces.
+func goroutineID() uint64 {
+	var buf [64]byte
+	stack := buf[:runtime.Stack(buf[:], false)]
+	// Stack starts with "goroutine <id> [<state>]:".
+	stack = bytes.TrimPrefix(stack, []byte("goroutine "))
+	stack = stack[:bytes.IndexByte(stack, ' ')]
+	id, _ := strconv.ParseUint(string(stack), 10, 64)
+	return id
+}
+
+// track registers current goroutine as running task t. Returned function
+// unregisters it.
+func (n *nursery) track(t task) (untrack func()) {
+	id := goroutineID()
+
+	n.trackedMu.Lock()
+	if n.tracked == nil {
+		n.tracked = make(map[uint64]task)
+	}
+	n.tracked[id] = t
+	n.trackedMu.Unlock()
+
+	return func() {
+		n.trackedMu.Lock()
+		delete(n.tracked, id)
+		n.trackedMu.Unlock()
+	}
+}
+
+// stacks returns current stack of tracked goroutines.
+func (n *nursery) stacks() map[uint64]string {
+	buf := make([]byte, 64*1024)
+	for {
+		size := runtime.Stack(buf, true)
+		if size < len(buf) {
+			buf = buf[:size]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	n.trackedMu.Lock()
+	defer n.trackedMu.Unlock()
+
+	stacks := make(map[uint64]string, len(n.tracked))
+	for _, stack := range bytes.Split(buf, []byte("\n\n")) {
+		id, _, _ := bytes.Cut(bytes.TrimPrefix(stack, []byte("goroutine ")), []byte(" "))
+		goid, err := strconv.ParseUint(string(id), 10, 64)
+		if err != nil {
+			continue
+		}
+		if _, isTracked := n.tracked[goid]; isTracked {
+			stacks[goid] = string(stack)
+		}
+	}
+
+	return stacks
+}
+
+// writeStacks writes current stack of tracked goroutines to w.
+func (n *nursery) writeStacks(w io.Writer) {
+	stacks := n.stacks()
+
+	n.trackedMu.Lock()
+	defer n.trackedMu.Unlock()
+
+	for goid, stack := range stacks {
+		t, isTracked := n.tracked[goid]
+		if !isTracked {
+			continue
+		}
+		name := t.name
+		if name == "" {
+			name = "#" + strconv.Itoa(t.index)
+		}
+		fmt.Fprintf(w, "nursery goroutine %v:\n%v\n\n", name, stack)
+	}
+}
+
+// ActiveStacks returns current stack trace of running goroutines of nursery n
+// by index. Stacks are extracted from a dump of all goroutines of the
+// process, it is expensive and meant for debugging. Stack tracking must be
+// enabled using WithStackTracking option, otherwise returned map is empty.
+func ActiveStacks(n Nursery) map[int]string {
+	return mustNursery(n, "ActiveStacks").activeStacks()
+}
+
+func (n *nursery) activeStacks() map[int]string {
+	stacks := n.stacks()
+
+	n.trackedMu.Lock()
+	defer n.trackedMu.Unlock()
+
+	result := make(map[int]string, len(stacks))
+	for goid, stack := range stacks {
+		if t, isTracked := n.tracked[goid]; isTracked {
+			result[t.index] = stack
+		}
+	}
+
+	return result
+}
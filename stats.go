@@ -0,0 +1,106 @@
+package conc
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Stats holds statistics of a nursery. Internal block goroutine isn't
+// accounted.
+type Stats struct {
+	// Active is the number of goroutines currently executing.
+	Active int
+	// Pending is the number of goroutines spawned but not yet started.
+	Pending int
+	// Completed is the number of goroutines that returned a nil error.
+	Completed int64
+	// Failed is the number of goroutines that returned a non-nil error.
+	Failed int64
+	// Panicked is the number of goroutines that panicked.
+	Panicked int64
+	// Peak is the maximum number of goroutines that executed concurrently.
+	Peak int
+	// Limit is the current maximum number of goroutines executing
+	// concurrently, it is adjusted at runtime by WithErrorRateThrottle. Zero
+	// means unlimited.
+	Limit int
+}
+
+// StatsOf returns a snapshot of statistics of nursery n.
+func StatsOf(n Nursery) Stats {
+	return mustNursery(n, "StatsOf").stats()
+}
+
+func (n *nursery) stats() Stats {
+	s := Stats{
+		Active:    int(n.activeCount.Load()),
+		Pending:   int(n.queuedCount.Load()),
+		Completed: n.completedCount.Load(),
+		Failed:    n.failedCount.Load(),
+		Panicked:  n.panickedCount.Load(),
+		Peak:      int(n.peakCount.Load()),
+		Limit:     n.maxGoroutines,
+	}
+	if n.throttle != nil {
+		s.Limit = int(n.throttle.sem.limit())
+		if n.maxGoroutines > 0 {
+			s.Limit = min(s.Limit, n.maxGoroutines)
+		}
+	}
+	return s
+}
+
+// WriteMetrics writes statistics of nursery n to w in OpenMetrics text
+// format. Metrics names are prefixed with nursery name (see WithName).
+func WriteMetrics(n Nursery, w io.Writer) error {
+	impl := mustNursery(n, "WriteMetrics")
+	stats := impl.stats()
+	prefix := metricName(impl.name)
+
+	metrics := []struct {
+		name, kind, help string
+		value            int64
+	}{
+		{"active", "gauge", "Number of goroutines currently executing.", int64(stats.Active)},
+		{"pending", "gauge", "Number of goroutines spawned but not yet started.", int64(stats.Pending)},
+		{"completed", "counter", "Number of goroutines that returned a nil error.", stats.Completed},
+		{"failed", "counter", "Number of goroutines that returned an error.", stats.Failed},
+		{"panicked", "counter", "Number of goroutines that panicked.", stats.Panicked},
+		{"peak", "gauge", "Maximum number of goroutines executed concurrently.", int64(stats.Peak)},
+	}
+
+	var b strings.Builder
+	for _, m := range metrics {
+		name := prefix + "_" + m.name
+		sample := name
+		if m.kind == "counter" {
+			sample += "_total"
+		}
+		fmt.Fprintf(&b, "# TYPE %v %v\n", name, m.kind)
+		fmt.Fprintf(&b, "# HELP %v %v\n", name, m.help)
+		fmt.Fprintf(&b, "%v %v\n", sample, m.value)
+	}
+	b.WriteString("# EOF\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// metricName converts s to a valid OpenMetrics metric name. Empty string is
+// converted to "nursery".
+func metricName(s string) string {
+	if s == "" {
+		return "nursery"
+	}
+
+	name := []byte(s)
+	for i, c := range name {
+		valid := c == '_' || c == ':' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+			(i > 0 && c >= '0' && c <= '9')
+		if !valid {
+			name[i] = '_'
+		}
+	}
+	return string(name)
+}
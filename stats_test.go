@@ -0,0 +1,75 @@
+package conc
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	var buf bytes.Buffer
+
+	Block(func(n Nursery) error {
+		n.Go(func() error { return nil })
+		n.Go(func() error { return nil })
+		n.Go(func() error { return io.EOF })
+		n.Go(func() error { panic("foo") })
+
+		deadline := time.Now().Add(time.Second)
+		for {
+			s := StatsOf(n)
+			if s.Completed+s.Failed+s.Panicked == 4 && s.Active == 0 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("goroutines didn't complete: %+v", s)
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+		if err := WriteMetrics(n, &buf); err != nil {
+			t.Fatal(err)
+		}
+		return nil
+	}, WithName("api-server"), WithFirstPanicAsError(), WithErrorHandler(func(error) {}))
+
+	output := buf.String()
+	if !strings.HasSuffix(output, "# EOF\n") {
+		t.Fatalf("output doesn't end with # EOF:\n%v", output)
+	}
+
+	// Validate lines against OpenMetrics text format.
+	metadata := regexp.MustCompile(`^# (TYPE [a-zA-Z_:][a-zA-Z0-9_:]* (gauge|counter)|HELP [a-zA-Z_:][a-zA-Z0-9_:]* .+)$`)
+	sample := regexp.MustCompile(`^([a-zA-Z_:][a-zA-Z0-9_:]*) (-?[0-9]+)$`)
+	samples := make(map[string]string)
+	lines := strings.Split(strings.TrimSuffix(output, "# EOF\n"), "\n")
+	for _, line := range lines[:len(lines)-1] {
+		if metadata.MatchString(line) {
+			continue
+		}
+		m := sample.FindStringSubmatch(line)
+		if m == nil {
+			t.Fatalf("invalid OpenMetrics line %q", line)
+		}
+		samples[m[1]] = m[2]
+	}
+
+	expected := map[string]string{
+		"api_server_active":          "0",
+		"api_server_pending":         "0",
+		"api_server_completed_total": "2",
+		"api_server_failed_total":    "1",
+		"api_server_panicked_total":  "1",
+	}
+	for name, value := range expected {
+		if samples[name] != value {
+			t.Fatalf("metric %v is %q instead of %q:\n%v", name, samples[name], value, output)
+		}
+	}
+	if _, ok := samples["api_server_peak"]; !ok {
+		t.Fatalf("peak metric missing:\n%v", output)
+	}
+}
@@ -0,0 +1,405 @@
+package conc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// emitLimiter returns rate limiter of emitted values of nursery n, if any.
+func emitLimiter(n Nursery) RateLimiter {
+	if impl, ok := n.(*nursery); ok {
+		return impl.emitLimiter
+	}
+	return nil
+}
+
+// BlockStream starts a nursery block where produce function and goroutines it
+// spawns emit values consumed concurrently by consume function. Results
+// channel is closed once produce function and all goroutines it spawned have
+// returned. Emit returns without sending value if nursery is canceled or
+// consume function returned. Emit rate can be limited using
+// WithEmitRateLimit option.
+func BlockStream[T any](produce func(n Nursery, emit func(T)) error, consume func(ctx context.Context, results <-chan T) error, opts ...BlockOption) error {
+	return Block(func(n Nursery) error {
+		results := make(chan T)
+		consumed := make(chan struct{})
+		n.Go(func() error {
+			defer close(consumed)
+			return consume(n, results)
+		})
+
+		limiter := emitLimiter(n)
+		emit := func(v T) {
+			if limiter != nil && limiter.Wait(n) != nil {
+				return
+			}
+			select {
+			case results <- v:
+			case <-consumed:
+			case <-n.Done():
+			}
+		}
+
+		defer close(results)
+		return Block(func(producers Nursery) error {
+			return produce(producers, emit)
+		}, WithContext(n))
+	}, opts...)
+}
+
+// StreamWithDone spawns producers in nursery n and returns a channel of values
+// they emitted. Emitted values are buffered up to the given capacity. Done
+// channel is closed once all producers have returned while out channel is
+// closed once remaining buffered values have been received, even if nursery
+// is canceled. This lets consumers distinguish between producers completion
+// and end of stream. Consumers must receive from out until it is closed,
+// otherwise block of n never ends. Emit
+// is paced by rate limiter of n, see WithEmitRateLimit. Producers are passed
+// to StreamWithDone instead of sharing a returned emit function so it knows
+// when the last one has returned.
+func StreamWithDone[T any](n Nursery, buffer int, producers ...func(ctx context.Context, emit func(T)) error) (out <-chan T, done <-chan struct{}) {
+	buffered := make(chan T, buffer)
+	outCh := make(chan T)
+	doneCh := make(chan struct{})
+
+	limiter := emitLimiter(n)
+	emit := func(v T) {
+		if limiter != nil && limiter.Wait(n) != nil {
+			return
+		}
+		select {
+		case buffered <- v:
+		case <-n.Done():
+		}
+	}
+
+	var remaining atomic.Int32
+	remaining.Add(int32(len(producers)))
+	producerDone := func() {
+		if remaining.Add(-1) == 0 {
+			close(doneCh)
+			close(buffered)
+		}
+	}
+	if len(producers) == 0 {
+		close(doneCh)
+		close(buffered)
+	}
+
+	// Forwarder is spawned first so it isn't dropped if a producer cancels
+	// nursery. Channels are closed even if tasks are dropped because nursery
+	// is canceled.
+	goFinally(n, func() error {
+		// Buffered values are forwarded even once nursery is canceled.
+		for v := range buffered {
+			outCh <- v
+		}
+		return nil
+	}, func() { close(outCh) })
+
+	for _, p := range producers {
+		producer := p
+		goFinally(n, func() error {
+			return producer(n, emit)
+		}, producerDone)
+	}
+
+	return outCh, doneCh
+}
+
+// Generate spawns gen and the given number of workers in nursery n. Values
+// emitted by gen are processed by workers. Emit blocks until a worker is
+// available, providing backpressure to gen. Emit returns without sending value
+// once nursery is canceled. Emit is paced by rate limiter of n, see
+// WithEmitRateLimit. Errors returned by fn are handled as goroutines
+// errors, workers keep processing values after an error.
+func Generate[T any](n Nursery, gen func(ctx context.Context, emit func(T)) error, workers int, fn func(context.Context, T) error) {
+	items := make(chan T)
+
+	limiter := emitLimiter(n)
+	n.Go(func() error {
+		defer close(items)
+		return gen(n, func(v T) {
+			if limiter != nil && limiter.Wait(n) != nil {
+				return
+			}
+			select {
+			case items <- v:
+			case <-n.Done():
+			}
+		})
+	})
+
+	for i := 0; i < workers; i++ {
+		n.Go(func() error {
+			for item := range items {
+				if err := fn(n, item); err != nil {
+					reportError(n, err)
+				}
+			}
+			return nil
+		})
+	}
+}
+
+// ProcessResults starts a nursery block where producers function and
+// goroutines it spawns emit values processed concurrently by the given number
+// of consumers goroutines. Non-nil errors returned by process are handled as
+// goroutines errors, consumers keep processing values after an error. It
+// returns processed values in completion order and block error. Emit rate can
+// be limited using WithEmitRateLimit option.
+func ProcessResults[T, R any](ctx context.Context, producers func(n Nursery, emit func(T)) error, consumers int, process func(context.Context, T) (R, error), opts ...BlockOption) ([]R, error) {
+	var mu sync.Mutex
+	var results []R
+
+	err := Block(func(n Nursery) error {
+		items := make(chan T)
+		for i := 0; i < consumers; i++ {
+			n.Go(func() error {
+				for item := range items {
+					r, err := process(n, item)
+					if err != nil {
+						// Keep consuming so producers aren't blocked.
+						reportError(n, err)
+						continue
+					}
+					mu.Lock()
+					results = append(results, r)
+					mu.Unlock()
+				}
+				return nil
+			})
+		}
+
+		limiter := emitLimiter(n)
+		emit := func(v T) {
+			if limiter != nil && limiter.Wait(n) != nil {
+				return
+			}
+			select {
+			case items <- v:
+			case <-n.Done():
+			}
+		}
+
+		defer close(items)
+		return Block(func(p Nursery) error {
+			return producers(p, emit)
+		}, WithContext(n))
+	}, append([]BlockOption{WithContext(ctx)}, opts...)...)
+
+	return results, err
+}
+
+// Indexed holds a value along its index in a stream.
+type Indexed[T any] struct {
+	Index int
+	Value T
+}
+
+// PipeOrdered spawns the given number of workers in nursery n applying fn to
+// values received from in and returns a channel of results emitted in
+// ascending index order. Input values must be received in ascending
+// consecutive index order starting from 0. Results completed ahead of the next
+// expected index are held in a reorder buffer: at most workers values are
+// processed or buffered at a time, a slow value stalls other workers once the
+// window is full. Non-nil errors returned by fn are returned to the nursery.
+// Output channel is closed once all values have been emitted.
+func PipeOrdered[T, R any](n Nursery, in <-chan Indexed[T], workers int, fn func(context.Context, T) (R, error)) <-chan Indexed[R] {
+	out := make(chan Indexed[R])
+	results := make(chan Indexed[R])
+	window := make(chan struct{}, workers)
+
+	// Channels are closed even if tasks are dropped because nursery is
+	// canceled.
+	goFinally(n, func() error {
+		return Block(func(pipe Nursery) error {
+			for i := 0; i < workers; i++ {
+				pipe.Go(func() error {
+					for {
+						select {
+						case window <- struct{}{}:
+						case <-pipe.Done():
+							return nil
+						}
+
+						var item Indexed[T]
+						var ok bool
+						select {
+						case item, ok = <-in:
+							if !ok {
+								return nil
+							}
+						case <-pipe.Done():
+							return nil
+						}
+
+						r, err := fn(pipe, item.Value)
+						if err != nil {
+							return err
+						}
+
+						select {
+						case results <- Indexed[R]{Index: item.Index, Value: r}:
+						case <-pipe.Done():
+							return nil
+						}
+					}
+				})
+			}
+			return nil
+		}, WithContext(n))
+	}, func() { close(results) })
+
+	// Reorder results.
+	goFinally(n, func() error {
+		buffer := make(map[int]R)
+		next := 0
+		for r := range results {
+			buffer[r.Index] = r.Value
+			for {
+				v, ok := buffer[next]
+				if !ok {
+					break
+				}
+				delete(buffer, next)
+
+				select {
+				case out <- Indexed[R]{Index: next, Value: v}:
+				case <-n.Done():
+					return nil
+				}
+				<-window
+				next++
+			}
+		}
+		return nil
+	}, func() { close(out) })
+
+	return out
+}
+
+// Stage is a stage of a pipeline started with Stages.
+type Stage struct {
+	// Workers is the number of goroutines executing Transform, it must be
+	// positive.
+	Workers int
+	// Transform transforms a value received from previous stage.
+	Transform func(context.Context, any) (any, error)
+}
+
+// Stages starts a pipeline in a nursery block where values received from
+// input flow through stages in order. Each stage forwards its transformed
+// values to the next one through an unbuffered channel, providing
+// backpressure. Returned channel delivers values transformed by last stage and
+// is closed once input is closed and all values were processed or pipeline is
+// canceled. An error returned by a transform cancels the pipeline regardless
+// of error handler options. Wait blocks until pipeline has ended and returns
+// block error or, if error handler dropped it, the first transform error. If a
+// stage is invalid, pipeline isn't started, returned channel is closed and
+// wait returns an error.
+func Stages[T any](ctx context.Context, input <-chan T, stages []Stage, opts ...BlockOption) (out <-chan any, wait func() error) {
+	outCh := make(chan any)
+	ended := make(chan struct{})
+	var err error
+	wait = func() error {
+		<-ended
+		return err
+	}
+
+	for i, s := range stages {
+		if s.Workers <= 0 || s.Transform == nil {
+			err = fmt.Errorf("invalid pipeline stage %v: positive workers and transform are required", i)
+			close(outCh)
+			close(ended)
+			return outCh, wait
+		}
+	}
+
+	var stageErr error
+	var stageErrOnce sync.Once
+
+	go func() {
+		defer close(ended)
+		err = Block(func(n Nursery) error {
+			// Convert input to a chan of any.
+			in := outCh
+			if len(stages) > 0 {
+				in = make(chan any)
+			}
+			// Channels are closed even if tasks are dropped because nursery
+			// is canceled.
+			goFinally(n, func() error {
+				for {
+					select {
+					case v, ok := <-input:
+						if !ok {
+							return nil
+						}
+						select {
+						case in <- v:
+						case <-n.Done():
+							return nil
+						}
+					case <-n.Done():
+						return nil
+					}
+				}
+			}, func() { close(in) })
+
+			var prev <-chan any = in
+			for i, s := range stages {
+				stage := s
+				src := prev
+				dst := outCh
+				if i < len(stages)-1 {
+					dst = make(chan any)
+				}
+				prev = dst
+
+				goFinally(n, func() error {
+					return Block(func(workers Nursery) error {
+						for j := 0; j < stage.Workers; j++ {
+							workers.Go(func() error {
+								for {
+									var v any
+									var ok bool
+									select {
+									case v, ok = <-src:
+										if !ok {
+											return nil
+										}
+									case <-workers.Done():
+										return nil
+									}
+
+									r, err := stage.Transform(workers, v)
+									if err != nil {
+										stageErrOnce.Do(func() { stageErr = err })
+										n.(*nursery).cancel()
+										return err
+									}
+									select {
+									case dst <- r:
+									case <-workers.Done():
+										return nil
+									}
+								}
+							})
+						}
+						return nil
+					}, WithContext(n))
+				}, func() { close(dst) })
+			}
+			return nil
+		}, append([]BlockOption{WithContext(ctx)}, opts...)...)
+
+		if err == nil {
+			err = stageErr
+		}
+	}()
+
+	return outCh, wait
+}
@@ -0,0 +1,549 @@
+package conc
+
+import (
+	"context"
+	"io"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBlockStream(t *testing.T) {
+	t.Run("RoundTrip", func(t *testing.T) {
+		sum := 0
+		err := BlockStream(func(n Nursery, emit func(int)) error {
+			for i := 1; i <= 10; i++ {
+				value := i
+				n.Go(func() error {
+					emit(value)
+					return nil
+				})
+			}
+			return nil
+		}, func(ctx context.Context, results <-chan int) error {
+			for v := range results {
+				sum += v
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if sum != 55 {
+			t.Fatalf("consumed values sum is %v instead of 55", sum)
+		}
+	})
+
+	t.Run("ConsumerReturnsEarly", func(t *testing.T) {
+		err := BlockStream(func(n Nursery, emit func(int)) error {
+			for i := 0; i < 10; i++ {
+				n.Go(func() error {
+					emit(i)
+					return nil
+				})
+			}
+			return nil
+		}, func(ctx context.Context, results <-chan int) error {
+			<-results
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("WithEmitRateLimit", func(t *testing.T) {
+		limiter := tickerLimiter{time.NewTicker(5 * time.Millisecond)}
+		defer limiter.Stop()
+
+		start := time.Now()
+		count := 0
+		err := BlockStream(func(n Nursery, emit func(int)) error {
+			for i := 0; i < 5; i++ {
+				emit(i)
+			}
+			return nil
+		}, func(ctx context.Context, results <-chan int) error {
+			for range results {
+				count++
+			}
+			return nil
+		}, WithEmitRateLimit(limiter))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != 5 {
+			t.Fatalf("%v values consumed instead of 5", count)
+		}
+		if time.Since(start) < 20*time.Millisecond {
+			t.Fatal("producer wasn't paced by emit rate limit")
+		}
+	})
+
+	t.Run("WithEmitRateLimit/Cancel", func(t *testing.T) {
+		limiter := tickerLimiter{time.NewTicker(time.Hour)}
+		defer limiter.Stop()
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		start := time.Now()
+		_ = BlockStream(func(n Nursery, emit func(int)) error {
+			emit(1)
+			return nil
+		}, func(ctx context.Context, results <-chan int) error {
+			for range results {
+			}
+			return nil
+		}, WithContext(ctx), WithEmitRateLimit(limiter))
+		if time.Since(start) > time.Second {
+			t.Fatal("cancel didn't unblock rate limited producer")
+		}
+	})
+}
+
+func TestStreamWithDone(t *testing.T) {
+	t.Run("DoneBeforeOut", func(t *testing.T) {
+		var received []int
+		Block(func(n Nursery) error {
+			produce := func(ctx context.Context, emit func(int)) error {
+				emit(1)
+				emit(2)
+				return nil
+			}
+			out, done := StreamWithDone(n, 4, produce, produce)
+
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("done channel not closed after producers returned")
+			}
+
+			select {
+			case _, ok := <-out:
+				if !ok {
+					t.Fatal("out channel closed before buffer was drained")
+				}
+				received = append(received, 0)
+			case <-time.After(time.Second):
+				t.Fatal("out channel blocked")
+			}
+
+			for range out {
+				received = append(received, 0)
+			}
+			return nil
+		})
+
+		if len(received) != 4 {
+			t.Fatalf("%v values received instead of 4", len(received))
+		}
+	})
+
+	t.Run("DrainOnCancel", func(t *testing.T) {
+		var received int
+		Block(func(n Nursery) error {
+			out, done := StreamWithDone(n, 4, func(ctx context.Context, emit func(int)) error {
+				for i := 0; i < 4; i++ {
+					emit(i)
+				}
+				// Cancel with buffered values.
+				n.(*nursery).cancel()
+				return nil
+			})
+
+			<-done
+			for range out {
+				received++
+			}
+			return nil
+		})
+
+		if received != 4 {
+			t.Fatalf("%v values received instead of 4", received)
+		}
+	})
+
+	t.Run("CanceledContext", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// Tasks are dropped randomly on a canceled nursery.
+		for i := 0; i < 100; i++ {
+			Block(func(n Nursery) error {
+				produce := func(ctx context.Context, emit func(int)) error {
+					emit(1)
+					return nil
+				}
+				out, done := StreamWithDone(n, 1, produce, produce)
+
+				<-done
+				for range out {
+				}
+				return nil
+			}, WithContext(ctx), WithMaxGoroutines(1))
+		}
+	})
+
+	t.Run("NoProducers", func(t *testing.T) {
+		Block(func(n Nursery) error {
+			out, done := StreamWithDone[int](n, 0)
+			<-done
+			if _, ok := <-out; ok {
+				t.Fatal("out channel delivered a value without producers")
+			}
+			return nil
+		})
+	})
+}
+
+func TestGenerate(t *testing.T) {
+	var processed, inFlight, maxInFlight atomic.Int32
+
+	Block(func(n Nursery) error {
+		Generate(n, func(ctx context.Context, emit func(int)) error {
+			for i := 0; i < 100; i++ {
+				v := inFlight.Add(1)
+				for {
+					m := maxInFlight.Load()
+					if v <= m || maxInFlight.CompareAndSwap(m, v) {
+						break
+					}
+				}
+				emit(i)
+			}
+			return nil
+		}, 3, func(ctx context.Context, i int) error {
+			time.Sleep(100 * time.Microsecond)
+			processed.Add(1)
+			inFlight.Add(-1)
+			return nil
+		})
+		return nil
+	})
+
+	if processed.Load() != 100 {
+		t.Fatalf("%v items processed instead of 100", processed.Load())
+	}
+	// 3 items processed by workers and 1 being emitted.
+	if maxInFlight.Load() > 4 {
+		t.Fatalf("%v items in flight, backpressure not applied", maxInFlight.Load())
+	}
+}
+
+func TestGenerateEmitRateLimit(t *testing.T) {
+	limiter := tickerLimiter{time.NewTicker(5 * time.Millisecond)}
+	defer limiter.Stop()
+
+	start := time.Now()
+	Block(func(n Nursery) error {
+		Generate(n, func(ctx context.Context, emit func(int)) error {
+			for i := 0; i < 5; i++ {
+				emit(i)
+			}
+			return nil
+		}, 2, func(ctx context.Context, i int) error {
+			return nil
+		})
+		return nil
+	}, WithEmitRateLimit(limiter))
+
+	if time.Since(start) < 20*time.Millisecond {
+		t.Fatal("generator wasn't paced by emit rate limit")
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	var errs []error
+	var processed atomic.Int32
+	err := Block(func(n Nursery) error {
+		Generate(n, func(ctx context.Context, emit func(int)) error {
+			for i := 0; i < 10; i++ {
+				emit(i)
+			}
+			return nil
+		}, 2, func(ctx context.Context, i int) error {
+			processed.Add(1)
+			if i%2 == 0 {
+				return io.EOF
+			}
+			return nil
+		})
+		return nil
+	}, WithCollectErrors(&errs))
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if processed.Load() != 10 {
+		t.Fatalf("%v items processed instead of 10", processed.Load())
+	}
+	if len(errs) != 5 {
+		t.Fatalf("%v error(s) collected instead of 5", len(errs))
+	}
+}
+
+func TestProcessResults(t *testing.T) {
+	// Producer waits for first result before emitting remaining items, this
+	// only completes if producers and consumers run concurrently.
+	processed := make(chan struct{}, 100)
+	results, err := ProcessResults(context.Background(), func(n Nursery, emit func(int)) error {
+		emit(0)
+		select {
+		case <-processed:
+		case <-time.After(time.Second):
+			t.Error("consumers don't run concurrently with producers")
+		}
+		for i := 1; i < 10; i++ {
+			v := i
+			n.Go(func() error {
+				emit(v)
+				return nil
+			})
+		}
+		return nil
+	}, 3, func(ctx context.Context, i int) (int, error) {
+		processed <- struct{}{}
+		return i * 2, nil
+	})
+
+	if err != nil {
+		t.Fatalf("ProcessResults returned %v instead of nil", err)
+	}
+	slices.Sort(results)
+	if !slices.Equal(results, []int{0, 2, 4, 6, 8, 10, 12, 14, 16, 18}) {
+		t.Fatalf("ProcessResults returned %v, some items weren't processed", results)
+	}
+}
+
+func TestProcessResultsEmitRateLimit(t *testing.T) {
+	limiter := tickerLimiter{time.NewTicker(5 * time.Millisecond)}
+	defer limiter.Stop()
+
+	start := time.Now()
+	results, err := ProcessResults(context.Background(), func(n Nursery, emit func(int)) error {
+		for i := 0; i < 5; i++ {
+			emit(i)
+		}
+		return nil
+	}, 2, func(ctx context.Context, i int) (int, error) {
+		return i, nil
+	}, WithEmitRateLimit(limiter))
+
+	if err != nil || len(results) != 5 {
+		t.Fatalf("ProcessResults returned (%v, %v)", results, err)
+	}
+	if time.Since(start) < 20*time.Millisecond {
+		t.Fatal("producers weren't paced by emit rate limit")
+	}
+}
+
+func TestProcessResultsErrors(t *testing.T) {
+	results, err := ProcessResults(context.Background(), func(n Nursery, emit func(int)) error {
+		for i := 0; i < 10; i++ {
+			emit(i)
+		}
+		return nil
+	}, 2, func(ctx context.Context, i int) (int, error) {
+		if i%2 == 0 {
+			return 0, io.EOF
+		}
+		return i, nil
+	}, WithIgnoreErrors())
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	slices.Sort(results)
+	if !slices.Equal(results, []int{1, 3, 5, 7, 9}) {
+		t.Fatalf("ProcessResults returned %v instead of [1 3 5 7 9]", results)
+	}
+}
+
+// tickerLimiter is a RateLimiter allowing an event per tick.
+type tickerLimiter struct {
+	*time.Ticker
+}
+
+func (l tickerLimiter) Wait(ctx context.Context) error {
+	select {
+	case <-l.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestPipeOrdered(t *testing.T) {
+	var indices, values []int
+	Block(func(n Nursery) error {
+		in := make(chan Indexed[int])
+		n.Go(func() error {
+			defer close(in)
+			for i := 0; i < 20; i++ {
+				in <- Indexed[int]{Index: i, Value: i}
+			}
+			return nil
+		})
+
+		// Even values complete after odd ones.
+		out := PipeOrdered(n, in, 4, func(ctx context.Context, v int) (int, error) {
+			if v%2 == 0 {
+				time.Sleep(time.Millisecond)
+			}
+			return v * 10, nil
+		})
+		for r := range out {
+			indices = append(indices, r.Index)
+			values = append(values, r.Value)
+		}
+		return nil
+	})
+
+	if len(indices) != 20 {
+		t.Fatalf("%v results emitted instead of 20", len(indices))
+	}
+	for i := 0; i < 20; i++ {
+		if indices[i] != i || values[i] != i*10 {
+			t.Fatalf("results aren't in input index order: indices %v values %v", indices, values)
+		}
+	}
+}
+
+func TestPipeOrderedCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Tasks are dropped randomly on a canceled nursery.
+	for i := 0; i < 100; i++ {
+		Block(func(n Nursery) error {
+			in := make(chan Indexed[int])
+			out := PipeOrdered(n, in, 2, func(ctx context.Context, v int) (int, error) {
+				return v, nil
+			})
+			for range out {
+			}
+			return nil
+		}, WithContext(ctx), WithMaxGoroutines(1))
+	}
+}
+
+func TestStages(t *testing.T) {
+	input := make(chan int)
+	go func() {
+		defer close(input)
+		for i := 1; i <= 10; i++ {
+			input <- i
+		}
+	}()
+
+	out, wait := Stages(context.Background(), input, []Stage{
+		{Workers: 3, Transform: func(ctx context.Context, v any) (any, error) {
+			return v.(int) * 2, nil
+		}},
+		{Workers: 2, Transform: func(ctx context.Context, v any) (any, error) {
+			return strconv.Itoa(v.(int)), nil
+		}},
+	})
+
+	var results []string
+	for v := range out {
+		results = append(results, v.(string))
+	}
+	slices.Sort(results)
+	expected := []string{"10", "12", "14", "16", "18", "2", "20", "4", "6", "8"}
+	if !slices.Equal(results, expected) {
+		t.Fatalf("pipeline produced %v instead of %v", results, expected)
+	}
+	if err := wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	t.Run("InvalidStage", func(t *testing.T) {
+		out, wait := Stages(context.Background(), input, []Stage{{Workers: 0}})
+		if wait() == nil {
+			t.Fatal("invalid stage accepted")
+		}
+		if _, ok := <-out; ok {
+			t.Fatal("output channel of invalid pipeline isn't closed")
+		}
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		input := make(chan int)
+		out, wait := Stages(ctx, input, []Stage{
+			{Workers: 2, Transform: func(ctx context.Context, v any) (any, error) {
+				return v, nil
+			}},
+		})
+
+		input <- 1
+		<-out
+		// Input is still open.
+		cancel()
+
+		for range out {
+		}
+		if err := wait(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("CanceledContext", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// Tasks are dropped randomly on a canceled nursery.
+		for i := 0; i < 100; i++ {
+			out, wait := Stages(ctx, make(chan int), []Stage{
+				{Workers: 1, Transform: func(ctx context.Context, v any) (any, error) {
+					return v, nil
+				}},
+			})
+			for range out {
+			}
+			if err := wait(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+	})
+
+	t.Run("TransformError", func(t *testing.T) {
+		for _, opt := range []BlockOption{WithIgnoreErrors(), WithCollectErrors(&[]error{})} {
+			input := make(chan int)
+			stop := make(chan struct{})
+			go func() {
+				// Input isn't closed before pipeline ends.
+				for i := 0; ; i++ {
+					select {
+					case input <- i:
+					case <-stop:
+						return
+					}
+				}
+			}()
+
+			out, wait := Stages(context.Background(), input, []Stage{
+				{Workers: 2, Transform: func(ctx context.Context, v any) (any, error) {
+					if v.(int) == 3 {
+						return nil, io.EOF
+					}
+					return v, nil
+				}},
+				{Workers: 1, Transform: func(ctx context.Context, v any) (any, error) {
+					return v, nil
+				}},
+			}, opt)
+
+			for range out {
+			}
+			close(stop)
+			if err := wait(); err != io.EOF {
+				t.Fatalf("pipeline returned %v instead of io.EOF", err)
+			}
+		}
+	})
+}
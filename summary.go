@@ -0,0 +1,76 @@
+package conc
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// SummaryError is the error returned by blocks using WithSummaryError option.
+type SummaryError struct {
+	// Total is the number of spawned goroutines.
+	Total int
+	// Failed is the number of goroutines that returned an error or panicked.
+	Failed int
+	// Panics is the number of goroutines that panicked.
+	Panics int
+	// Errors contains errors of failed goroutines and recovered panics
+	// converted to errors. Error returned by block function, if any, is
+	// last.
+	Errors []error
+}
+
+// Error implements error.
+func (se *SummaryError) Error() string {
+	return fmt.Sprintf("%v of %v tasks failed (%v, %v)", se.Failed, se.Total,
+		plural(se.Failed-se.Panics, "error"), plural(se.Panics, "panic"))
+}
+
+// Unwrap returns collected errors.
+func (se *SummaryError) Unwrap() []error {
+	return se.Errors
+}
+
+func plural(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%v %v", count, noun)
+	}
+	return fmt.Sprintf("%v %vs", count, noun)
+}
+
+// summary collects goroutines failures.
+type summary struct {
+	mu     sync.Mutex
+	errs   []error
+	panics int
+}
+
+func (s *summary) add(err error, isPanic bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errs = append(s.errs, err)
+	if isPanic {
+		s.panics++
+	}
+}
+
+// err returns summary error of total goroutines or nil if no goroutine
+// failed.
+func (s *summary) err(total int, blockErr error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.errs) == 0 {
+		return nil
+	}
+
+	errs := slices.Clip(s.errs)
+	if blockErr != nil {
+		errs = append(errs, blockErr)
+	}
+	return &SummaryError{
+		Total:  total,
+		Failed: len(s.errs),
+		Panics: s.panics,
+		Errors: errs,
+	}
+}
@@ -0,0 +1,52 @@
+package conc
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestWithSummaryError(t *testing.T) {
+	err := Block(func(n Nursery) error {
+		for i := 0; i < 7; i++ {
+			n.Go(func() error {
+				return nil
+			})
+		}
+		n.Go(func() error {
+			return io.EOF
+		})
+		n.Go(func() error {
+			return io.ErrUnexpectedEOF
+		})
+		n.Go(func() error {
+			panic("foo")
+		})
+		return nil
+	}, WithSummaryError())
+
+	var summary *SummaryError
+	if !errors.As(err, &summary) {
+		t.Fatalf("block returned %v instead of a summary error", err)
+	}
+	if err.Error() != "3 of 10 tasks failed (2 errors, 1 panic)" {
+		t.Fatalf("summary error message is %q", err.Error())
+	}
+	if !errors.Is(err, io.EOF) || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatal("goroutine errors aren't reachable from summary error")
+	}
+	var gp GoroutinePanic
+	if !errors.As(err, &gp) || gp.Value != "foo" {
+		t.Fatal("panic isn't reachable from summary error")
+	}
+
+	err = Block(func(n Nursery) error {
+		n.Go(func() error {
+			return nil
+		})
+		return nil
+	}, WithSummaryError())
+	if err != nil {
+		t.Fatalf("block without failures returned %v instead of nil", err)
+	}
+}
@@ -0,0 +1,136 @@
+package conc
+
+import (
+	"context"
+	"errors"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+)
+
+// RestartPolicy define how a supervised worker is restarted.
+type RestartPolicy struct {
+	// MaxRestarts is the maximum number of restarts of a failing worker. A
+	// negative value means unlimited restarts.
+	MaxRestarts int
+	// Backoff is the delay between a failure and the restart.
+	Backoff time.Duration
+	// MaxLifetime is the maximum duration of a worker run. A worker running
+	// longer is canceled and restarted even without failure. Those restarts
+	// aren't accounted in MaxRestarts. Zero means unlimited lifetime.
+	MaxLifetime time.Duration
+}
+
+var errMaxLifetime = errors.New("supervised worker max lifetime exceeded")
+
+// Supervise spawns worker in nursery n and restarts it according to policy
+// when it returns an error. Worker returning nil isn't restarted. Last error is
+// returned to the nursery once restarts are exhausted. Panics aren't recovered
+// by the supervisor and are forwarded as usual.
+func Supervise(n Nursery, policy RestartPolicy, worker func(context.Context) error) {
+	n.Go(func() error {
+		restarts := 0
+		for {
+			expired, err := runSupervised(n, policy, worker)
+			if n.Err() != nil {
+				return err
+			}
+			if expired {
+				continue
+			}
+			if err == nil {
+				return nil
+			}
+
+			if policy.MaxRestarts >= 0 && restarts >= policy.MaxRestarts {
+				return err
+			}
+			restarts++
+
+			Sleep(n, policy.Backoff)
+		}
+	})
+}
+
+// runSupervised executes a single run of worker. It reports whether worker
+// was canceled because it exceeded its max lifetime.
+func runSupervised(n Nursery, policy RestartPolicy, worker func(context.Context) error) (bool, error) {
+	if policy.MaxLifetime <= 0 {
+		return false, worker(n)
+	}
+
+	ctx, cancel := context.WithTimeoutCause(n, policy.MaxLifetime, errMaxLifetime)
+	defer cancel()
+
+	err := worker(ctx)
+	return context.Cause(ctx) == errMaxLifetime, err
+}
+
+// ResilientPipe spawns the given number of workers in nursery n applying fn to
+// values received from in and returns a channel of results. A worker whose
+// call to fn panics or returns an error is restarted according to policy and
+// the failed input is dropped. MaxRestarts is shared among all workers, once
+// exhausted the failure is returned to the nursery. Recovered panics are
+// returned as GoroutinePanic. MaxLifetime of policy is ignored. Output channel
+// is closed once all workers have returned.
+func ResilientPipe[T, R any](n Nursery, in <-chan T, workers int, policy RestartPolicy, fn func(context.Context, T) (R, error)) <-chan R {
+	out := make(chan R)
+	var restarts atomic.Int32
+
+	n.Go(func() error {
+		defer close(out)
+		return Block(func(pipe Nursery) error {
+			for i := 0; i < workers; i++ {
+				pipe.Go(func() error {
+					for {
+						var item T
+						var ok bool
+						select {
+						case item, ok = <-in:
+							if !ok {
+								return nil
+							}
+						case <-pipe.Done():
+							return nil
+						}
+
+						r, err := callRecover(pipe, fn, item)
+						if err != nil {
+							if pipe.Err() != nil {
+								return nil
+							}
+							if policy.MaxRestarts >= 0 && int(restarts.Add(1)) > policy.MaxRestarts {
+								return err
+							}
+
+							// Restart worker.
+							Sleep(pipe, policy.Backoff)
+							continue
+						}
+
+						select {
+						case out <- r:
+						case <-pipe.Done():
+							return nil
+						}
+					}
+				})
+			}
+
+			return nil
+		}, WithContext(n))
+	})
+
+	return out
+}
+
+// callRecover calls fn and returns recovered panic as a GoroutinePanic error.
+func callRecover[T, R any](ctx context.Context, fn func(context.Context, T) (R, error), v T) (r R, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = GoroutinePanic{Value: p, Stack: string(debug.Stack())}
+		}
+	}()
+
+	return fn(ctx, v)
+}
@@ -0,0 +1,140 @@
+package conc
+
+import (
+	"context"
+	"io"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSupervise(t *testing.T) {
+	t.Run("RestartOnError", func(t *testing.T) {
+		var runs atomic.Int32
+		err := Block(func(n Nursery) error {
+			Supervise(n, RestartPolicy{MaxRestarts: 2}, func(ctx context.Context) error {
+				runs.Add(1)
+				return io.EOF
+			})
+			return nil
+		})
+
+		if err != io.EOF {
+			t.Fatalf("block returned %v instead of io.EOF", err)
+		}
+		if runs.Load() != 3 {
+			t.Fatalf("worker ran %v time(s) instead of 3 times", runs.Load())
+		}
+	})
+
+	t.Run("MaxLifetime", func(t *testing.T) {
+		t.Run("LongLived", func(t *testing.T) {
+			var runs atomic.Int32
+			Block(func(n Nursery) error {
+				Supervise(n, RestartPolicy{MaxLifetime: 20 * time.Millisecond}, func(ctx context.Context) error {
+					runs.Add(1)
+					<-ctx.Done()
+					return ctx.Err()
+				})
+				return nil
+			}, WithTimeout(70*time.Millisecond))
+
+			if runs.Load() < 3 {
+				t.Fatalf("worker ran %v time(s) instead of at least 3 times", runs.Load())
+			}
+		})
+
+		t.Run("ShortLived", func(t *testing.T) {
+			var runs atomic.Int32
+			Block(func(n Nursery) error {
+				Supervise(n, RestartPolicy{MaxLifetime: 50 * time.Millisecond}, func(ctx context.Context) error {
+					runs.Add(1)
+					time.Sleep(time.Millisecond)
+					return nil
+				})
+				return nil
+			})
+
+			if runs.Load() != 1 {
+				t.Fatalf("worker ran %v time(s) instead of 1 time", runs.Load())
+			}
+		})
+	})
+}
+
+func TestResilientPipe(t *testing.T) {
+	t.Run("ReplacePanickedWorker", func(t *testing.T) {
+		var results []int
+		err := Block(func(n Nursery) error {
+			in := make(chan int)
+			n.Go(func() error {
+				defer close(in)
+				for i := 0; i < 10; i++ {
+					in <- i
+				}
+				return nil
+			})
+
+			out := ResilientPipe(n, in, 1, RestartPolicy{MaxRestarts: 1}, func(ctx context.Context, i int) (int, error) {
+				if i == 3 {
+					panic("bad input")
+				}
+				return i, nil
+			})
+			for r := range out {
+				results = append(results, r)
+			}
+			return nil
+		})
+
+		if err != nil {
+			t.Fatalf("block returned %v instead of nil", err)
+		}
+		if !slices.Equal(results, []int{0, 1, 2, 4, 5, 6, 7, 8, 9}) {
+			t.Fatalf("pipe returned %v, inputs after panic weren't processed", results)
+		}
+	})
+
+	t.Run("RestartsExhausted", func(t *testing.T) {
+		err := Block(func(n Nursery) error {
+			in := make(chan int, 3)
+			in <- 1
+			in <- 2
+			in <- 3
+			close(in)
+
+			for range ResilientPipe(n, in, 2, RestartPolicy{MaxRestarts: 1}, func(ctx context.Context, i int) (int, error) {
+				return 0, io.EOF
+			}) {
+			}
+			return nil
+		})
+
+		if err != io.EOF {
+			t.Fatalf("block returned %v instead of io.EOF", err)
+		}
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		err := Block(func(n Nursery) error {
+			// Input is never closed.
+			in := make(chan int)
+			out := ResilientPipe(n, in, 2, RestartPolicy{}, func(ctx context.Context, i int) (int, error) {
+				return i, nil
+			})
+
+			in <- 1
+			<-out
+			cancel()
+			for range out {
+			}
+			return nil
+		}, WithContext(ctx))
+
+		if err != nil {
+			t.Fatalf("block returned %v instead of nil", err)
+		}
+	})
+}
@@ -0,0 +1,128 @@
+package conc
+
+import (
+	"context"
+	"sync"
+)
+
+// Mutex is a mutual exclusion lock whose Lock method respects context
+// cancellation. Zero value is an unlocked mutex. It must not be copied after
+// first use.
+type Mutex struct {
+	once sync.Once
+	ch   chan struct{}
+}
+
+func (m *Mutex) init() {
+	m.once.Do(func() {
+		m.ch = make(chan struct{}, 1)
+	})
+}
+
+// Lock locks m. If the lock is already in use, calling goroutine blocks until
+// mutex is available or context is canceled. Context error is returned in the
+// latter case.
+func (m *Mutex) Lock(ctx context.Context) error {
+	m.init()
+	select {
+	case m.ch <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// TryLock tries to lock m without blocking and reports whether it succeeded.
+func (m *Mutex) TryLock() bool {
+	m.init()
+	select {
+	case m.ch <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Unlock unlocks m. It panics if m isn't locked.
+func (m *Mutex) Unlock() {
+	m.init()
+	select {
+	case <-m.ch:
+	default:
+		panic("conc: unlock of unlocked mutex")
+	}
+}
+
+// Cond is a condition variable whose Wait method respects context
+// cancellation. Unlike sync.Cond, goroutines waiting on a Cond can be canceled
+// during nursery shutdown.
+type Cond struct {
+	// L is held while observing or changing the condition.
+	L sync.Locker
+
+	mu      sync.Mutex
+	waiters []chan struct{}
+}
+
+// NewCond returns a new Cond with Locker l.
+func NewCond(l sync.Locker) *Cond {
+	return &Cond{L: l}
+}
+
+// Wait atomically unlocks c.L and suspends calling goroutine until it is woken
+// up by Signal or Broadcast or context is canceled. c.L is locked again before
+// Wait returns. Context error is returned if context was canceled before
+// goroutine was woken up. As with sync.Cond, caller should check condition in
+// a loop.
+func (c *Cond) Wait(ctx context.Context) error {
+	ch := make(chan struct{})
+	c.mu.Lock()
+	c.waiters = append(c.waiters, ch)
+	c.mu.Unlock()
+
+	c.L.Unlock()
+	defer c.L.Lock()
+
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, w := range c.waiters {
+		if w == ch {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return ctx.Err()
+		}
+	}
+
+	// Woken up concurrently with cancellation.
+	return nil
+}
+
+// Signal wakes one goroutine waiting on c, if there is any.
+func (c *Cond) Signal() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.signal()
+}
+
+func (c *Cond) signal() {
+	if len(c.waiters) == 0 {
+		return
+	}
+	close(c.waiters[0])
+	c.waiters = c.waiters[1:]
+}
+
+// Broadcast wakes all goroutines waiting on c.
+func (c *Cond) Broadcast() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, ch := range c.waiters {
+		close(ch)
+	}
+	c.waiters = nil
+}
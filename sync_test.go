@@ -0,0 +1,103 @@
+package conc
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMutex(t *testing.T) {
+	t.Run("LockUnlock", func(t *testing.T) {
+		var mu Mutex
+		if err := mu.Lock(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if mu.TryLock() {
+			t.Fatal("locked mutex acquired twice")
+		}
+		mu.Unlock()
+		if !mu.TryLock() {
+			t.Fatal("unlocked mutex can't be acquired")
+		}
+	})
+
+	t.Run("CancelLock", func(t *testing.T) {
+		var mu Mutex
+		var lockErr error
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		_ = mu.Lock(context.Background())
+		Block(func(n Nursery) error {
+			n.Go(func() error {
+				lockErr = mu.Lock(n)
+				return nil
+			})
+
+			time.Sleep(time.Millisecond)
+			cancel()
+			return nil
+		}, WithContext(ctx))
+
+		if lockErr != context.Canceled {
+			t.Fatalf("Lock returned %v instead of context.Canceled", lockErr)
+		}
+	})
+}
+
+func TestCond(t *testing.T) {
+	t.Run("Signal", func(t *testing.T) {
+		var mu sync.Mutex
+		cond := NewCond(&mu)
+		ready := false
+
+		Block(func(n Nursery) error {
+			n.Go(func() error {
+				mu.Lock()
+				defer mu.Unlock()
+				for !ready {
+					if err := cond.Wait(n); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			ready = true
+			cond.Signal()
+			mu.Unlock()
+			return nil
+		})
+	})
+
+	t.Run("CancelWait", func(t *testing.T) {
+		var mu sync.Mutex
+		cond := NewCond(&mu)
+		var waitErr error
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		Block(func(n Nursery) error {
+			n.Go(func() error {
+				mu.Lock()
+				waitErr = cond.Wait(n)
+				mu.Unlock()
+				return nil
+			})
+
+			time.Sleep(time.Millisecond)
+			cancel()
+			return nil
+		}, WithContext(ctx))
+
+		if waitErr != context.Canceled {
+			t.Fatalf("Wait returned %v instead of context.Canceled", waitErr)
+		}
+		if !mu.TryLock() {
+			t.Fatal("locker not unlocked after canceled Wait")
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package conc
+
+import (
+	"sync"
+)
+
+// throttleWindow is the number of goroutines outcomes observed between
+// adjustments of error rate throttle limit.
+const throttleWindow = 10
+
+// errorRateThrottle adjusts a concurrency limit to keep observed error rate
+// near a target.
+type errorRateThrottle struct {
+	sem      *weightedSemaphore
+	target   float64
+	min, max int
+
+	mu       sync.Mutex
+	observed int
+	errors   int
+}
+
+func newErrorRateThrottle(target float64, min, max int) *errorRateThrottle {
+	return &errorRateThrottle{
+		sem:    newWeightedSemaphore(int64(max)),
+		target: target,
+		min:    min,
+		max:    max,
+	}
+}
+
+// observe records outcome of a goroutine and adjusts limit once enough
+// outcomes were observed. Limit is increased by one if error rate is below
+// target and decreased by a quarter otherwise.
+func (t *errorRateThrottle) observe(failed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.observed++
+	if failed {
+		t.errors++
+	}
+	if t.observed < throttleWindow {
+		return
+	}
+
+	rate := float64(t.errors) / float64(t.observed)
+	t.observed, t.errors = 0, 0
+
+	limit := int(t.sem.limit())
+	if rate > t.target {
+		limit -= max(limit/4, 1)
+	} else {
+		limit++
+	}
+	t.sem.setMax(int64(min(max(limit, t.min), t.max)))
+}
@@ -0,0 +1,47 @@
+package conc
+
+import (
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithErrorRateThrottle(t *testing.T) {
+	// Simulated service failing requests above 5 concurrent requests.
+	const capacity = 5
+	var concurrent, failures, late atomic.Int32
+	var limit int
+
+	Block(func(n Nursery) error {
+		for i := 0; i < 600; i++ {
+			lateRequest := i >= 400
+			n.Go(func() error {
+				c := concurrent.Add(1)
+				defer concurrent.Add(-1)
+				time.Sleep(100 * time.Microsecond)
+				if c > capacity {
+					if lateRequest {
+						late.Add(1)
+					}
+					failures.Add(1)
+					return io.EOF
+				}
+				return nil
+			})
+		}
+		limit = StatsOf(n).Limit
+		return nil
+	}, WithErrorRateThrottle(0.1, 1, 50), WithIgnoreErrors())
+
+	if limit < 2 || limit > 2*capacity {
+		t.Fatalf("throttle settled at limit %v, service capacity is %v", limit, capacity)
+	}
+	// Error rate of last 200 requests once settled.
+	if rate := float64(late.Load()) / 200; rate > 0.3 {
+		t.Fatalf("error rate is %v once settled, target is 0.1", rate)
+	}
+	if failures.Load() == 0 {
+		t.Fatal("throttle didn't start at max limit")
+	}
+}
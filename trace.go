@@ -0,0 +1,55 @@
+package conc
+
+import "context"
+
+// Tracer starts tracing spans. It is a minimal interface that can be adapted
+// to a tracing library such as OpenTelemetry.
+type Tracer interface {
+	// Start starts a span with the given name and returns a context bearing
+	// it.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a tracing span started by a Tracer.
+type Span interface {
+	// SetAttribute sets an attribute of span.
+	SetAttribute(key, value string)
+	// RecordError records an error of the traced operation.
+	RecordError(err error)
+	// End ends span.
+	End()
+}
+
+// GoTraced is the same as GoNamed but routine is traced using tracer set with
+// WithTracer option. A span named name with baggage as attributes is started
+// and its context is passed to routine. Span records routine error, if any,
+// and ends once routine returns. Without tracer, routine receives nursery
+// context.
+func GoTraced(n Nursery, name string, baggage map[string]string, routine func(context.Context) error) {
+	impl, ok := n.(*nursery)
+	if !ok {
+		GoCtx(n, routine)
+		return
+	}
+	impl.goTraced(name, baggage, routine)
+}
+
+func (n *nursery) goTraced(name string, baggage map[string]string, routine func(context.Context) error) {
+	n.goNamed(name, func() error {
+		if n.tracer == nil {
+			return routine(n)
+		}
+
+		ctx, span := n.tracer.Start(n, name)
+		defer span.End()
+		for k, v := range baggage {
+			span.SetAttribute(k, v)
+		}
+
+		err := routine(ctx)
+		if err != nil {
+			span.RecordError(err)
+		}
+		return err
+	})
+}
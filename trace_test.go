@@ -0,0 +1,63 @@
+package conc
+
+import (
+	"context"
+	"io"
+	"maps"
+	"sync"
+	"testing"
+)
+
+type spanKey struct{}
+
+type testSpan struct {
+	name  string
+	attrs map[string]string
+	err   error
+	ended bool
+}
+
+func (s *testSpan) SetAttribute(key, value string) { s.attrs[key] = value }
+func (s *testSpan) RecordError(err error)          { s.err = err }
+func (s *testSpan) End()                           { s.ended = true }
+
+// testTracer records started spans.
+type testTracer struct {
+	mu    sync.Mutex
+	spans []*testSpan
+}
+
+func (t *testTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	span := &testSpan{name: name, attrs: make(map[string]string)}
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func TestGoTraced(t *testing.T) {
+	tracer := &testTracer{}
+	var ctxSpan any
+
+	Block(func(n Nursery) error {
+		GoTraced(n, "fetch", map[string]string{"user": "42", "region": "eu"}, func(ctx context.Context) error {
+			ctxSpan = ctx.Value(spanKey{})
+			return io.EOF
+		})
+		return nil
+	}, WithTracer(tracer), WithIgnoreErrors())
+
+	if len(tracer.spans) != 1 {
+		t.Fatalf("%v span(s) started instead of 1", len(tracer.spans))
+	}
+	span := tracer.spans[0]
+	if span.name != "fetch" || !maps.Equal(span.attrs, map[string]string{"user": "42", "region": "eu"}) {
+		t.Fatalf("span %q has attributes %v", span.name, span.attrs)
+	}
+	if span.err != io.EOF || !span.ended {
+		t.Fatalf("span recorded error %v and ended %v", span.err, span.ended)
+	}
+	if ctxSpan != span {
+		t.Fatal("routine context doesn't bear span")
+	}
+}
@@ -2,7 +2,11 @@ package conc
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"iter"
+	"slices"
+	"sync"
 	"sync/atomic"
 	"time"
 )
@@ -16,6 +20,30 @@ func Sleep(ctx context.Context, d time.Duration) {
 	}
 }
 
+// Until calls cond immediately and then every interval until it returns true
+// or an error. It returns nil once condition is met, cond error or context
+// error if context is canceled between polls.
+func Until(ctx context.Context, interval time.Duration, cond func(context.Context) (bool, error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		ok, err := cond(ctx)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
 type Job[T any] func(context.Context) (T, error)
 
 // All executes all jobs in separate goroutines and stores each result in
@@ -120,6 +148,32 @@ func doMap[T any](input []T, results []T, f func(context.Context, T) (T, error),
 	}, opts...)
 }
 
+// ErrLengthMismatch is returned by MapInto if input and output slices have
+// different lengths.
+var ErrLengthMismatch = errors.New("input and output slices length mismatch")
+
+// MapInto applies f to each element of input and stores result in output at the
+// same index. It returns ErrLengthMismatch before spawning any goroutine if
+// output and input lengths differ.
+func MapInto[T, R any](ctx context.Context, input []T, output []R, f func(context.Context, T) (R, error), opts ...BlockOption) error {
+	if len(input) != len(output) {
+		return fmt.Errorf("%w: %v != %v", ErrLengthMismatch, len(input), len(output))
+	}
+
+	return Block(func(n Nursery) error {
+		for i, v := range input {
+			value := v
+			r := &output[i]
+			n.Go(func() (err error) {
+				*r, err = f(n, value)
+				return err
+			})
+		}
+
+		return nil
+	}, append([]BlockOption{WithContext(ctx)}, opts...)...)
+}
+
 // Map2 applies f to each key, value pair of input and returns a new slice containing
 // mapped results.
 func Map2[K comparable, V any](input map[K]V, f func(context.Context, K, V) (K, V, error), opts ...BlockOption) (map[K]V, error) {
@@ -149,3 +203,401 @@ func doMap2[K comparable, V any](input map[K]V, results map[K]V, f func(context.
 		return nil
 	}, opts...)
 }
+
+// LoadAll loads value of each key in a separate goroutine and returns a map of
+// loaded values. Keys are deduplicated before loading so duplicate keys within
+// keys are loaded only once. Deduplication is scoped to a single call:
+// concurrent LoadAll calls don't share in-flight loads, callers needing
+// cross-call deduplication must wrap load with their own singleflight.
+func LoadAll[K comparable, V any](ctx context.Context, keys []K, load func(context.Context, K) (V, error), opts ...BlockOption) (map[K]V, error) {
+	results := make(map[K]V, len(keys))
+	var mu sync.Mutex
+
+	err := Block(func(n Nursery) error {
+		unique := make(map[K]struct{}, len(keys))
+		for _, k := range keys {
+			if _, duplicate := unique[k]; duplicate {
+				continue
+			}
+			unique[k] = struct{}{}
+
+			key := k
+			n.Go(func() error {
+				v, err := load(n, key)
+				if err != nil {
+					return err
+				}
+
+				mu.Lock()
+				results[key] = v
+				mu.Unlock()
+				return nil
+			})
+		}
+
+		return nil
+	}, append([]BlockOption{WithContext(ctx)}, opts...)...)
+
+	return results, err
+}
+
+// Stagger spawns each function in nursery n with the given interval between
+// starts. Spawning of remaining functions stops once nursery is canceled.
+func Stagger(n Nursery, interval time.Duration, fns ...func() error) {
+	n.Go(func() error {
+		for i, fn := range fns {
+			if i > 0 {
+				Sleep(n, interval)
+			}
+			if n.Err() != nil {
+				return nil
+			}
+			n.Go(fn)
+		}
+
+		return nil
+	})
+}
+
+// MapWithin applies f to each element of input in separate goroutines for at
+// most d. It returns results of calls that completed in time, indices of
+// elements whose call didn't complete and context.DeadlineExceeded if some
+// calls didn't complete. f must return once its context is done.
+func MapWithin[T, R any](ctx context.Context, d time.Duration, input []T, f func(context.Context, T) (R, error), opts ...BlockOption) ([]R, []int, error) {
+	results := make([]R, len(input))
+	completed := make([]bool, len(input))
+
+	err := Block(func(n Nursery) error {
+		for i, v := range input {
+			index := i
+			value := v
+			n.Go(func() error {
+				r, err := f(n, value)
+				if err != nil {
+					if n.Err() != nil {
+						// Didn't complete in time.
+						return nil
+					}
+					return err
+				}
+
+				results[index] = r
+				completed[index] = true
+				return nil
+			})
+		}
+
+		return nil
+	}, append([]BlockOption{WithContext(ctx), WithTimeout(d)}, opts...)...)
+
+	var incomplete []int
+	for i, ok := range completed {
+		if !ok {
+			incomplete = append(incomplete, i)
+		}
+	}
+	if err == nil && len(incomplete) > 0 {
+		err = ctx.Err()
+		if err == nil {
+			err = context.DeadlineExceeded
+		}
+	}
+
+	return results, incomplete, err
+}
+
+// Target is a queried backend of ScatterGather.
+type Target[T any] struct {
+	// Timeout bounds duration of Query. Zero means no timeout.
+	Timeout time.Duration
+	// Query queries target, it must return once its context is done.
+	Query func(context.Context) (T, error)
+}
+
+// ScatterGather queries all targets concurrently, each bounded by its own
+// timeout. It returns values and errors aligned with targets indices. Errors
+// don't cancel other queries and a target that timed out has
+// context.DeadlineExceeded error. Targets that were never queried (e.g.
+// because ctx was canceled) have an error wrapping ErrNotExecuted.
+func ScatterGather[T any](ctx context.Context, targets []Target[T], opts ...BlockOption) ([]T, []error) {
+	results := make([]T, len(targets))
+	errs := make([]error, len(targets))
+	for i := range errs {
+		errs[i] = ErrNotExecuted
+	}
+
+	err := Block(func(n Nursery) error {
+		for i, t := range targets {
+			index := i
+			target := t
+			n.Go(func() error {
+				ctx := context.Context(n)
+				if target.Timeout > 0 {
+					var cancel context.CancelFunc
+					ctx, cancel = context.WithTimeout(ctx, target.Timeout)
+					defer cancel()
+				}
+
+				results[index], errs[index] = target.Query(ctx)
+				return nil
+			})
+		}
+
+		return nil
+	}, append([]BlockOption{WithContext(ctx)}, opts...)...)
+
+	// Report why targets weren't queried.
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		for i, e := range errs {
+			if e == ErrNotExecuted {
+				errs[i] = fmt.Errorf("%w: %w", ErrNotExecuted, err)
+			}
+		}
+	}
+
+	return results, errs
+}
+
+// BestOf executes all functions in separate goroutines and waits up to d for
+// them. It returns the highest-scoring successful result among those that
+// completed in time and cancels remaining goroutines. If none succeeded in
+// time, first successful result completed afterward is returned. First error
+// is returned if all functions failed.
+func BestOf[T any](ctx context.Context, d time.Duration, score func(T) float64, fns ...func(context.Context) (T, error)) (T, error) {
+	var best T
+	var bestScore float64
+	found := false
+	var firstErr error
+
+	err := Block(func(n Nursery) error {
+		results := make(chan Result[T], len(fns))
+		for _, fn := range fns {
+			f := fn
+			n.Go(func() error {
+				v, err := f(n)
+				results <- Result[T]{Value: v, Err: err}
+				return nil
+			})
+		}
+
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+
+		timedOut := false
+		for received := 0; received < len(fns); {
+			select {
+			case r := <-results:
+				received++
+				if r.Err != nil {
+					if firstErr == nil {
+						firstErr = r.Err
+					}
+					continue
+				}
+				if s := score(r.Value); !found || s > bestScore {
+					best, bestScore, found = r.Value, s, true
+				}
+			case <-timer.C:
+				timedOut = true
+			case <-n.Done():
+				return n.Err()
+			}
+
+			if timedOut && found {
+				break
+			}
+		}
+
+		// Cancel remaining goroutines.
+		n.(*nursery).cancel()
+		return nil
+	}, WithContext(ctx))
+
+	if found {
+		return best, nil
+	}
+	if firstErr != nil {
+		return best, firstErr
+	}
+	return best, err
+}
+
+// Fallback executes primary and, if it fails, fallback. It returns result of
+// primary if it succeeded, result of fallback otherwise. If both fail, errors
+// are joined.
+func Fallback[T any](ctx context.Context, primary, fallback func(context.Context) (T, error)) (T, error) {
+	v, err := primary(ctx)
+	if err == nil {
+		return v, nil
+	}
+
+	fv, ferr := fallback(ctx)
+	if ferr != nil {
+		return fv, errors.Join(err, ferr)
+	}
+	return fv, nil
+}
+
+// FallbackConcurrent is the same as Fallback except primary and fallback are
+// executed concurrently. Fallback is canceled once primary succeeded.
+func FallbackConcurrent[T any](ctx context.Context, primary, fallback func(context.Context) (T, error)) (T, error) {
+	var p, f Result[T]
+
+	_ = Block(func(n Nursery) error {
+		n.Go(func() error {
+			f.Value, f.Err = fallback(n)
+			return nil
+		})
+
+		p.Value, p.Err = primary(n)
+		if p.Err == nil {
+			// Cancel fallback.
+			n.(*nursery).cancel()
+		}
+		return nil
+	}, WithContext(ctx))
+
+	if p.Err == nil {
+		return p.Value, nil
+	}
+	if f.Err != nil {
+		return f.Value, errors.Join(p.Err, f.Err)
+	}
+	return f.Value, nil
+}
+
+// ErrNotExecuted is returned by RetryBatch and ScatterGather for items that
+// were never executed.
+var ErrNotExecuted = errors.New("routine not executed")
+
+// RetryBatch applies fn to each item in separate goroutines, use
+// WithMaxGoroutines option to bound concurrency. Failing items are retried up
+// to attempts times in total, waiting backoff(attempt) after the given failed
+// attempt (starting from 1). It returns final errors aligned with items,
+// nil where an attempt eventually succeeded. Errors don't cancel other items.
+// Items that were never attempted (e.g. because ctx was canceled) have an
+// error wrapping ErrNotExecuted.
+func RetryBatch[T any](ctx context.Context, items []T, fn func(context.Context, T) error, attempts int, backoff func(int) time.Duration, opts ...BlockOption) []error {
+	errs := make([]error, len(items))
+	for i := range errs {
+		errs[i] = ErrNotExecuted
+	}
+
+	err := Block(func(n Nursery) error {
+		for i, v := range items {
+			index := i
+			item := v
+			n.Go(func() error {
+				for attempt := 1; ; attempt++ {
+					err := fn(n, item)
+					if err == nil || attempt >= attempts || n.Err() != nil {
+						errs[index] = err
+						return nil
+					}
+					Sleep(n, backoff(attempt))
+				}
+			})
+		}
+
+		return nil
+	}, append([]BlockOption{WithContext(ctx)}, opts...)...)
+
+	// Report why items weren't executed.
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		for i, e := range errs {
+			if e == ErrNotExecuted {
+				errs[i] = fmt.Errorf("%w: %w", ErrNotExecuted, err)
+			}
+		}
+	}
+
+	return errs
+}
+
+// ResumableMap applies f to each element of input in separate goroutines
+// except those whose index is in skip. Indices of completed elements,
+// including skipped ones, are passed in ascending order to checkpoint as
+// calls complete. Completions are coalesced while checkpoint is running so it
+// isn't called once per element. A job can be resumed after a crash by skipping
+// indices of the last checkpoint. Results of skipped elements are zero values.
+// An error returned by checkpoint cancels remaining calls.
+func ResumableMap[T, R any](ctx context.Context, input []T, f func(context.Context, T) (R, error), checkpoint func(completed []int) error, skip []int, opts ...BlockOption) ([]R, error) {
+	results := make([]R, len(input))
+
+	skipped := make([]bool, len(input))
+	var completed []int
+	for _, i := range skip {
+		if i >= 0 && i < len(input) && !skipped[i] {
+			skipped[i] = true
+			completed = append(completed, i)
+		}
+	}
+
+	completions := make(chan int, len(input))
+
+	err := Block(func(n Nursery) error {
+		n.Go(func() error {
+			defer close(completions)
+
+			return Block(func(wn Nursery) error {
+				for i, v := range input {
+					if skipped[i] {
+						continue
+					}
+
+					index := i
+					value := v
+					wn.Go(func() error {
+						r, err := f(wn, value)
+						if err != nil {
+							return err
+						}
+
+						results[index] = r
+						completions <- index
+						return nil
+					})
+				}
+
+				return nil
+			}, append([]BlockOption{WithContext(n)}, opts...)...)
+		})
+
+		n.Go(func() error {
+			for index := range completions {
+				completed = append(completed, index)
+
+				// Coalesce completions received while checkpointing.
+				for more := true; more; {
+					select {
+					case index, ok := <-completions:
+						if ok {
+							completed = append(completed, index)
+						} else {
+							more = false
+						}
+					default:
+						more = false
+					}
+				}
+
+				slices.Sort(completed)
+				if err := checkpoint(slices.Clone(completed)); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+
+		return nil
+	}, WithContext(ctx))
+
+	return results, err
+}
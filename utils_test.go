@@ -0,0 +1,433 @@
+package conc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoadAll(t *testing.T) {
+	t.Run("DuplicateKeys", func(t *testing.T) {
+		var calls atomic.Int32
+		results, err := LoadAll(context.Background(), []string{"a", "b", "a", "c", "b"},
+			func(ctx context.Context, key string) (string, error) {
+				calls.Add(1)
+				return key + key, nil
+			})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if calls.Load() != 3 {
+			t.Fatalf("load called %v times instead of 3", calls.Load())
+		}
+		if len(results) != 3 || results["a"] != "aa" || results["b"] != "bb" || results["c"] != "cc" {
+			t.Fatalf("unexpected results: %v", results)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		_, err := LoadAll(context.Background(), []int{1, 2, 3},
+			func(ctx context.Context, key int) (int, error) {
+				if key == 2 {
+					return 0, io.EOF
+				}
+				return key, nil
+			}, WithMaxGoroutines(1))
+		if err != io.EOF {
+			t.Fatalf("LoadAll returned %v instead of io.EOF", err)
+		}
+	})
+}
+
+func TestMapInto(t *testing.T) {
+	t.Run("Indices", func(t *testing.T) {
+		input := []int{1, 2, 3, 4}
+		output := make([]string, len(input))
+		err := MapInto(context.Background(), input, output, func(ctx context.Context, i int) (string, error) {
+			return strconv.Itoa(i * 2), nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !slices.Equal(output, []string{"2", "4", "6", "8"}) {
+			t.Fatalf("unexpected output: %v", output)
+		}
+	})
+
+	t.Run("LengthMismatch", func(t *testing.T) {
+		called := false
+		err := MapInto(context.Background(), []int{1, 2}, make([]int, 1), func(ctx context.Context, i int) (int, error) {
+			called = true
+			return i, nil
+		})
+		if !errors.Is(err, ErrLengthMismatch) {
+			t.Fatalf("MapInto returned %v instead of ErrLengthMismatch", err)
+		}
+		if called {
+			t.Fatal("function called despite length mismatch")
+		}
+	})
+}
+
+func TestStagger(t *testing.T) {
+	t.Run("Interval", func(t *testing.T) {
+		var mu sync.Mutex
+		var starts []time.Time
+		fn := func() error {
+			mu.Lock()
+			starts = append(starts, time.Now())
+			mu.Unlock()
+			return nil
+		}
+
+		Block(func(n Nursery) error {
+			Stagger(n, 10*time.Millisecond, fn, fn, fn)
+			return nil
+		})
+
+		if len(starts) != 3 {
+			t.Fatalf("%v functions started instead of 3", len(starts))
+		}
+		for i := 1; i < len(starts); i++ {
+			if d := starts[i].Sub(starts[i-1]); d < 10*time.Millisecond || d > 50*time.Millisecond {
+				t.Fatalf("functions %v and %v started %v apart instead of ~10ms", i-1, i, d)
+			}
+		}
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		var started atomic.Int32
+		fn := func() error {
+			started.Add(1)
+			return nil
+		}
+
+		Block(func(n Nursery) error {
+			Stagger(n, 10*time.Millisecond, fn, fn, fn, fn)
+			return nil
+		}, WithTimeout(15*time.Millisecond))
+
+		if started.Load() != 2 {
+			t.Fatalf("%v functions started instead of 2", started.Load())
+		}
+	})
+}
+
+func TestMapWithin(t *testing.T) {
+	input := []time.Duration{time.Millisecond, time.Second, 2 * time.Millisecond, time.Second}
+	results, incomplete, err := MapWithin(context.Background(), 50*time.Millisecond, input,
+		func(ctx context.Context, d time.Duration) (string, error) {
+			select {
+			case <-time.After(d):
+				return d.String(), nil
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+		})
+
+	if err != context.DeadlineExceeded {
+		t.Fatalf("MapWithin returned %v instead of context.DeadlineExceeded", err)
+	}
+	if !slices.Equal(incomplete, []int{1, 3}) {
+		t.Fatalf("incomplete indices are %v instead of [1 3]", incomplete)
+	}
+	if results[0] != "1ms" || results[2] != "2ms" {
+		t.Fatalf("completed results are missing: %v", results)
+	}
+}
+
+func TestUntil(t *testing.T) {
+	t.Run("ConditionMet", func(t *testing.T) {
+		calls := 0
+		err := Until(context.Background(), time.Millisecond, func(ctx context.Context) (bool, error) {
+			calls++
+			return calls == 3, nil
+		})
+		if err != nil || calls != 3 {
+			t.Fatalf("Until returned %v after %v call(s) instead of nil after 3 calls", err, calls)
+		}
+	})
+
+	t.Run("CancelDuringWait", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		start := time.Now()
+		err := Until(ctx, time.Hour, func(ctx context.Context) (bool, error) {
+			return false, nil
+		})
+		if err != context.Canceled {
+			t.Fatalf("Until returned %v instead of context.Canceled", err)
+		}
+		if time.Since(start) > time.Second {
+			t.Fatal("Until didn't return promptly on cancel")
+		}
+	})
+}
+
+func TestScatterGather(t *testing.T) {
+	query := func(v int, d time.Duration) func(context.Context) (int, error) {
+		return func(ctx context.Context) (int, error) {
+			select {
+			case <-time.After(d):
+				return v, nil
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			}
+		}
+	}
+
+	t.Run("Timeouts", func(t *testing.T) {
+		results, errs := ScatterGather(context.Background(), []Target[int]{
+			{Timeout: 100 * time.Millisecond, Query: query(1, time.Millisecond)},
+			{Timeout: 5 * time.Millisecond, Query: query(2, time.Second)},
+			{Query: query(3, time.Millisecond)},
+		})
+
+		if !slices.Equal(results, []int{1, 0, 3}) {
+			t.Fatalf("ScatterGather returned %v instead of [1 0 3]", results)
+		}
+		if errs[0] != nil || errs[1] != context.DeadlineExceeded || errs[2] != nil {
+			t.Fatalf("ScatterGather returned errors %v instead of [nil DeadlineExceeded nil]", errs)
+		}
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var queried atomic.Int32
+		targets := make([]Target[int], 200)
+		for i := range targets {
+			targets[i] = Target[int]{Query: func(ctx context.Context) (int, error) {
+				queried.Add(1)
+				return 1, nil
+			}}
+		}
+
+		results, errs := ScatterGather(ctx, targets, WithMaxGoroutines(1))
+		notExecuted := 0
+		for i, err := range errs {
+			switch {
+			case err == nil:
+				if results[i] != 1 {
+					t.Fatalf("target %v succeeded with value %v", i, results[i])
+				}
+			case errors.Is(err, ErrNotExecuted) && errors.Is(err, context.Canceled):
+				notExecuted++
+			default:
+				t.Fatalf("target %v returned unexpected error: %v", i, err)
+			}
+		}
+		if notExecuted+int(queried.Load()) != len(targets) {
+			t.Fatalf("%v targets not executed and %v queried out of %v", notExecuted, queried.Load(), len(targets))
+		}
+		if notExecuted == 0 {
+			t.Fatal("all targets were queried on a canceled context")
+		}
+	})
+}
+
+func TestBestOf(t *testing.T) {
+	strategy := func(v int, d time.Duration) func(context.Context) (int, error) {
+		return func(ctx context.Context) (int, error) {
+			select {
+			case <-time.After(d):
+				return v, nil
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			}
+		}
+	}
+	score := func(v int) float64 { return float64(v) }
+
+	t.Run("HighestScore", func(t *testing.T) {
+		v, err := BestOf(context.Background(), 50*time.Millisecond, score,
+			strategy(1, time.Millisecond),
+			strategy(3, 5*time.Millisecond),
+			strategy(5, time.Hour),
+		)
+		if v != 3 || err != nil {
+			t.Fatalf("BestOf returned (%v, %v) instead of (3, nil)", v, err)
+		}
+	})
+
+	t.Run("NoneInTime", func(t *testing.T) {
+		v, err := BestOf(context.Background(), time.Millisecond, score,
+			strategy(1, 10*time.Millisecond),
+			strategy(2, time.Hour),
+		)
+		if v != 1 || err != nil {
+			t.Fatalf("BestOf returned (%v, %v) instead of (1, nil)", v, err)
+		}
+	})
+
+	t.Run("AllFailed", func(t *testing.T) {
+		_, err := BestOf(context.Background(), time.Second, score, func(ctx context.Context) (int, error) {
+			return 0, io.EOF
+		})
+		if err != io.EOF {
+			t.Fatalf("BestOf returned %v instead of io.EOF", err)
+		}
+	})
+}
+
+func TestFallback(t *testing.T) {
+	failing := func(ctx context.Context) (int, error) { return 0, io.EOF }
+	succeeding := func(v int) func(context.Context) (int, error) {
+		return func(ctx context.Context) (int, error) { return v, nil }
+	}
+
+	for name, fallback := range map[string]func(context.Context, func(context.Context) (int, error), func(context.Context) (int, error)) (int, error){
+		"Sequential": Fallback[int],
+		"Concurrent": FallbackConcurrent[int],
+	} {
+		t.Run(name, func(t *testing.T) {
+			v, err := fallback(context.Background(), failing, succeeding(2))
+			if v != 2 || err != nil {
+				t.Fatalf("failing primary returned (%v, %v) instead of fallback (2, nil)", v, err)
+			}
+
+			v, err = fallback(context.Background(), succeeding(1), succeeding(2))
+			if v != 1 || err != nil {
+				t.Fatalf("succeeding primary returned (%v, %v) instead of (1, nil)", v, err)
+			}
+
+			_, err = fallback(context.Background(), failing, func(ctx context.Context) (int, error) {
+				return 0, io.ErrUnexpectedEOF
+			})
+			if !errors.Is(err, io.EOF) || !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Fatalf("both failing returned %v instead of joined errors", err)
+			}
+		})
+	}
+}
+
+func TestRetryBatch(t *testing.T) {
+	var calls [3]atomic.Int32
+	var backoffs []int
+	var mu sync.Mutex
+
+	errs := RetryBatch(context.Background(), []int{0, 1, 2}, func(ctx context.Context, i int) error {
+		c := calls[i].Add(1)
+		switch i {
+		case 1:
+			// Fails twice then succeeds.
+			if c <= 2 {
+				return io.EOF
+			}
+		case 2:
+			return io.ErrUnexpectedEOF
+		}
+		return nil
+	}, 3, func(attempt int) time.Duration {
+		mu.Lock()
+		backoffs = append(backoffs, attempt)
+		mu.Unlock()
+		return time.Millisecond
+	}, WithMaxGoroutines(2))
+
+	if errs[0] != nil || errs[1] != nil || errs[2] != io.ErrUnexpectedEOF {
+		t.Fatalf("RetryBatch returned %v instead of [nil nil ErrUnexpectedEOF]", errs)
+	}
+	if calls[0].Load() != 1 || calls[1].Load() != 3 || calls[2].Load() != 3 {
+		t.Fatalf("items attempted %v, %v and %v time(s) instead of 1, 3 and 3", calls[0].Load(), calls[1].Load(), calls[2].Load())
+	}
+	if len(backoffs) != 4 {
+		t.Fatalf("backoff called %v time(s) instead of 4", len(backoffs))
+	}
+
+	t.Run("Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		errs := RetryBatch(ctx, []int{0, 1, 2}, func(ctx context.Context, i int) error {
+			return nil
+		}, 3, func(int) time.Duration { return 0 })
+
+		for i, err := range errs {
+			if !errors.Is(err, ErrNotExecuted) || !errors.Is(err, context.Canceled) {
+				t.Fatalf("item %v of canceled batch has error %v", i, err)
+			}
+		}
+	})
+}
+
+func TestResumableMap(t *testing.T) {
+	t.Run("Checkpoint", func(t *testing.T) {
+		var checkpoints [][]int
+		results, err := ResumableMap(context.Background(), []int{1, 2, 3, 4, 5}, func(_ context.Context, v int) (int, error) {
+			time.Sleep(time.Duration(v) * time.Millisecond)
+			return v * 2, nil
+		}, func(completed []int) error {
+			checkpoints = append(checkpoints, completed)
+			return nil
+		}, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !slices.Equal(results, []int{2, 4, 6, 8, 10}) {
+			t.Fatalf("unexpected results: %v", results)
+		}
+
+		if len(checkpoints) == 0 {
+			t.Fatal("checkpoint never called")
+		}
+		for i := 1; i < len(checkpoints); i++ {
+			if len(checkpoints[i]) <= len(checkpoints[i-1]) {
+				t.Fatalf("checkpoints aren't growing: %v", checkpoints)
+			}
+		}
+		if last := checkpoints[len(checkpoints)-1]; !slices.Equal(last, []int{0, 1, 2, 3, 4}) {
+			t.Fatalf("last checkpoint is %v instead of [0 1 2 3 4]", last)
+		}
+	})
+
+	t.Run("Skip", func(t *testing.T) {
+		var mu sync.Mutex
+		var processed []int
+		var last []int
+		results, err := ResumableMap(context.Background(), []int{1, 2, 3, 4}, func(_ context.Context, v int) (int, error) {
+			mu.Lock()
+			processed = append(processed, v)
+			mu.Unlock()
+			return v * 2, nil
+		}, func(completed []int) error {
+			last = completed
+			return nil
+		}, []int{0, 2})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		slices.Sort(processed)
+		if !slices.Equal(processed, []int{2, 4}) {
+			t.Fatalf("processed %v instead of [2 4]", processed)
+		}
+		if !slices.Equal(results, []int{0, 4, 0, 8}) {
+			t.Fatalf("unexpected results: %v", results)
+		}
+		if !slices.Equal(last, []int{0, 1, 2, 3}) {
+			t.Fatalf("last checkpoint is %v instead of [0 1 2 3]", last)
+		}
+	})
+
+	t.Run("CheckpointError", func(t *testing.T) {
+		_, err := ResumableMap(context.Background(), []int{1, 2, 3}, func(_ context.Context, v int) (int, error) {
+			return v, nil
+		}, func(completed []int) error {
+			return io.EOF
+		}, nil)
+		if err != io.EOF {
+			t.Fatalf("ResumableMap returned %v instead of io.EOF", err)
+		}
+	})
+}